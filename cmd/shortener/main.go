@@ -1,12 +1,18 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"embed"
+	"expvar"
 	"log"
+	"net"
+	"net/http"
 	"os"
+	"time"
 
-	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
 	"github.com/pressly/goose/v3"
 	"github.com/rs/zerolog"
 
@@ -14,12 +20,29 @@ import (
 	"github.com/llravell/go-shortener/internal/app"
 	"github.com/llravell/go-shortener/internal/entity"
 	"github.com/llravell/go-shortener/internal/repo"
+	"github.com/llravell/go-shortener/internal/rest"
+	"github.com/llravell/go-shortener/internal/rest/middleware"
 	"github.com/llravell/go-shortener/internal/usecase"
 	"github.com/llravell/go-shortener/logger"
+	"github.com/llravell/go-shortener/pkg/geoip"
 	"github.com/llravell/go-shortener/pkg/workerpool"
 )
 
-const urlDeleteWorkersAmount = 4
+const (
+	urlDeleteWorkerIdleTimeout  = 30 * time.Second
+	urlDeleteWorkTimeout        = 30 * time.Second
+	urlDeleteJobsResumeInterval = 20 * time.Second
+	dbReplicaCheckInterval      = 10 * time.Second
+	urlClickQueueSize           = 1024
+	urlClickMemoLimit           = 10000
+)
+
+var urlDeleteRetryPolicy = workerpool.RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   100 * time.Millisecond,
+	MaxDelay:    2 * time.Second,
+	Jitter:      0.5,
+}
 
 //go:embed migrations/*.sql
 var embedMigrations embed.FS
@@ -50,6 +73,28 @@ func runMigrations(db *sql.DB) error {
 	return nil
 }
 
+// openDBPool открывает пул подключений к базе с настройками размера из конфига.
+func openDBPool(dsn string, cfg *config.Config) (*pgxpool.Pool, error) {
+	poolConfig, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.DBMaxConns > 0 {
+		poolConfig.MaxConns = int32(cfg.DBMaxConns) //nolint:gosec // размер пула заведомо меньше int32
+	}
+
+	if cfg.DBMaxConnIdleTime.Duration > 0 {
+		poolConfig.MaxConnIdleTime = cfg.DBMaxConnIdleTime.Duration
+	}
+
+	if cfg.DBMaxConnLifetime.Duration > 0 {
+		poolConfig.MaxConnLifetime = cfg.DBMaxConnLifetime.Duration
+	}
+
+	return pgxpool.NewWithConfig(context.Background(), poolConfig)
+}
+
 func prepareMemoryURLRepo(
 	memoRepo *repo.URLMemoRepo,
 	cfg *config.Config,
@@ -78,6 +123,28 @@ func prepareMemoryURLRepo(
 	}
 }
 
+func prepareURLDeleteJobRepo(
+	pool *pgxpool.Pool,
+	cfg *config.Config,
+	log zerolog.Logger,
+) (usecase.URLDeleteJobRepo, func() error) {
+	if pool != nil {
+		return repo.NewURLDeleteJobDatabaseRepo(pool), func() error { return nil }
+	}
+
+	if cfg.DeleteJobsStoragePath == "" {
+		return repo.NewURLDeleteJobMemoRepo(), func() error { return nil }
+	}
+
+	fileRepo, err := repo.NewURLDeleteJobFileRepo(cfg.DeleteJobsStoragePath)
+	if err != nil {
+		log.Error().Err(err).Msg("delete jobs journal initialize failed")
+		os.Exit(1)
+	}
+
+	return fileRepo, fileRepo.Close
+}
+
 //nolint:funlen
 func main() {
 	printBuildInfo()
@@ -87,30 +154,81 @@ func main() {
 		log.Fatalf("config error: %s", err)
 	}
 
-	var db *sql.DB
+	if !entity.IsRedirectCode(cfg.DefaultRedirectCode) {
+		log.Fatalf("config error: unsupported default redirect code %d", cfg.DefaultRedirectCode)
+	}
+
+	if cfg.NotActiveCode < http.StatusBadRequest || http.StatusText(cfg.NotActiveCode) == "" {
+		log.Fatalf("config error: not active code %d is not an error status", cfg.NotActiveCode)
+	}
+
+	trustedProxies, err := middleware.ParseTrustedProxies(cfg.TrustedProxies)
+	if err != nil {
+		log.Fatalf("config error: %s", err)
+	}
+
+	var trustedSubnet *net.IPNet
+
+	if cfg.TrustedSubnet != "" {
+		_, trustedSubnet, err = net.ParseCIDR(cfg.TrustedSubnet)
+		if err != nil {
+			log.Fatalf("config error: invalid trusted subnet %q: %s", cfg.TrustedSubnet, err)
+		}
+	}
+
+	var (
+		pool *pgxpool.Pool
+		db   *sql.DB
+	)
 
 	if cfg.DatabaseDsn != "" {
-		db, err = sql.Open("pgx", cfg.DatabaseDsn)
+		pool, err = openDBPool(cfg.DatabaseDsn, cfg)
 		if err != nil {
 			log.Fatalf("open db error: %s", err)
 		}
 
+		db = stdlib.OpenDBFromPool(pool)
+
 		err = runMigrations(db)
 		if err != nil {
 			db.Close()
+			pool.Close()
 			log.Fatalf("migration running error: %s", err)
 		}
 
+		defer pool.Close()
 		defer db.Close()
 	}
 
+	var replicaPool *pgxpool.Pool
+
+	if cfg.DatabaseDsn != "" && cfg.DatabaseReplicaDsn != "" {
+		replicaPool, err = openDBPool(cfg.DatabaseReplicaDsn, cfg)
+		if err != nil {
+			log.Fatalf("open replica db error: %s", err)
+		}
+
+		defer replicaPool.Close()
+	}
+
 	log := logger.Get()
 	defer logger.Close()
 
-	var urlRepo usecase.URLRepo
+	var urlRepo usecase.URLBatchDeleteRepo
+
+	watchCtx, stopWatch := context.WithCancel(context.Background())
+	defer stopWatch()
 
 	if cfg.DatabaseDsn != "" {
-		urlRepo = repo.NewURLDatabaseRepo(db)
+		var opts []repo.URLDatabaseRepoOption
+		if replicaPool != nil {
+			opts = append(opts, repo.Replica(replicaPool))
+		}
+
+		dbRepo := repo.NewURLDatabaseRepo(pool, opts...)
+		urlRepo = dbRepo
+
+		go dbRepo.WatchReplica(watchCtx, dbReplicaCheckInterval)
 	} else {
 		memoRepo := repo.NewURLMemoRepo()
 		cancel := prepareMemoryURLRepo(memoRepo, cfg, log)
@@ -124,26 +242,133 @@ func main() {
 		}()
 	}
 
-	urlDeleteWorkerPool := workerpool.New[*usecase.URLDeleteWork](urlDeleteWorkersAmount)
+	// Метрики приложения доступны из доверенной подсети на /metrics и, в режиме отладки, в /debug/vars.
+	metrics := expvar.NewMap("shortener")
+
+	var urlUseCaseRepo usecase.URLRepo = urlRepo
+
+	if cfg.DeleteBatchWindow.Duration > 0 {
+		urlDeleteBatcher := usecase.NewURLDeleteBatcher(
+			urlRepo,
+			cfg.DeleteBatchWindow.Duration,
+			cfg.DeleteBatchMaxHashes,
+			log,
+		)
+		urlUseCaseRepo = urlDeleteBatcher
+
+		metrics.Set("url_delete_batches", expvar.Func(func() any {
+			return urlDeleteBatcher.Stats()
+		}))
+
+		defer urlDeleteBatcher.Close()
+	}
+
+	if cfg.URLCacheSize > 0 {
+		urlUseCaseRepo = usecase.NewURLCache(
+			urlUseCaseRepo,
+			cfg.URLCacheSize,
+			cfg.URLCacheTTL.Duration,
+			cfg.URLCacheNegativeTTL.Duration,
+		)
+	}
+
+	urlDeleteJobRepo, closeURLDeleteJobRepo := prepareURLDeleteJobRepo(pool, cfg, log)
+
+	defer func() {
+		err = closeURLDeleteJobRepo()
+		if err != nil {
+			log.Error().Err(err).Msg("delete jobs repo close failed")
+		}
+	}()
+
+	urlDeleteWorkerPool := workerpool.New(
+		cfg.DeleteWorkersMin,
+		workerpool.MaxWorkers[*usecase.URLDeleteWork](cfg.DeleteWorkersMax),
+		workerpool.IdleTimeout[*usecase.URLDeleteWork](urlDeleteWorkerIdleTimeout),
+		workerpool.WorkTimeout[*usecase.URLDeleteWork](urlDeleteWorkTimeout),
+		workerpool.OnPanic(func(work *usecase.URLDeleteWork, recovered any, stack []byte) {
+			log.Error().
+				Interface("panic", recovered).
+				Bytes("stack", stack).
+				Str("jobID", work.JobID).
+				Msg("delete work panicked")
+		}),
+		workerpool.Retry[*usecase.URLDeleteWork](urlDeleteRetryPolicy),
+		workerpool.QueueSize[*usecase.URLDeleteWork](cfg.DeleteQueueSize),
+		workerpool.DeadLetter(func(work *usecase.URLDeleteWork, err error) {
+			log.Error().
+				Err(err).
+				Str("jobID", work.JobID).
+				Msg("delete work moved to dead letter")
+
+			work.Fail(context.Background(), err)
+		}),
+	)
+
+	var urlClickRepo usecase.ClickRepo = repo.NewURLClickMemoRepo(urlClickMemoLimit)
+	if pool != nil {
+		urlClickRepo = repo.NewURLClickDatabaseRepo(pool)
+	}
+
+	urlClickWriter := usecase.NewURLClickWriter(urlClickRepo, urlClickQueueSize, log)
+	defer urlClickWriter.Close()
+
+	metrics.Set("url_clicks", expvar.Func(func() any {
+		return urlClickWriter.Stats()
+	}))
+
+	urlUseCaseOpts := []usecase.URLUseCaseOption{
+		usecase.DefaultRedirectCode(cfg.DefaultRedirectCode),
+		usecase.RedirectCacheMaxAge(cfg.RedirectCacheMaxAge.Duration),
+		usecase.NotActiveCode(cfg.NotActiveCode),
+		usecase.ClickAnalytics(urlClickWriter),
+	}
+
+	if cfg.GeoIPDatabasePath != "" {
+		geoDB, err := geoip.Open(cfg.GeoIPDatabasePath)
+		if err != nil {
+			log.Error().Err(err).Msg("geoip database open failed")
+			os.Exit(1)
+		}
+
+		defer geoDB.Close()
+
+		urlUseCaseOpts = append(urlUseCaseOpts, usecase.GeoLocation(geoDB))
+	}
 
 	urlUseCase := usecase.NewURLUseCase(
-		urlRepo,
+		urlUseCaseRepo,
+		urlDeleteJobRepo,
 		urlDeleteWorkerPool,
 		entity.NewRandomStringGenerator(),
 		cfg.BaseAddr,
 		log,
+		urlUseCaseOpts...,
 	)
 	healthUseCase := usecase.NewHealthUseCase(db)
 
 	urlDeleteWorkerPool.ProcessQueue()
 
+	metrics.Set("url_delete_workers", expvar.Func(func() any {
+		return urlDeleteWorkerPool.Stats()
+	}))
+
+	go urlUseCase.WatchDeleteJobs(watchCtx, urlDeleteJobsResumeInterval)
+
 	defer func() {
+		stopWatch()
 		urlDeleteWorkerPool.Close()
 
 		log.Info().Msg("delete worker pool finish waiting...")
 		urlDeleteWorkerPool.Wait()
 	}()
 
+	templates, err := rest.LoadTemplates(cfg.TemplatesDir)
+	if err != nil {
+		log.Error().Err(err).Str("dir", cfg.TemplatesDir).Msg("templates loading failed")
+		os.Exit(1)
+	}
+
 	app.New(
 		urlUseCase,
 		healthUseCase,
@@ -151,5 +376,9 @@ func main() {
 		app.Addr(cfg.Addr),
 		app.JWTSecret(cfg.JWTSecret),
 		app.IsDebug(cfg.AppEnv == "development"),
+		app.Templates(templates),
+		app.Metrics(metrics),
+		app.TrustedProxies(trustedProxies),
+		app.TrustedSubnet(trustedSubnet),
 	).Run()
 }
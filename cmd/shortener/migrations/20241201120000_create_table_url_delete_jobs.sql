@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE url_delete_jobs (
+  id UUID PRIMARY KEY,
+  user_uuid UUID NOT NULL,
+  hashes JSONB NOT NULL DEFAULT '[]',
+  status VARCHAR(16) NOT NULL,
+  error TEXT NOT NULL DEFAULT '',
+  results JSONB NOT NULL DEFAULT '[]',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_url_delete_jobs_pending
+ON url_delete_jobs(updated_at)
+WHERE status IN ('queued', 'running');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE url_delete_jobs;
+-- +goose StatementEnd
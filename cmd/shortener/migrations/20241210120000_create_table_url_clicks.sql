@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE url_clicks (
+  id BIGSERIAL PRIMARY KEY,
+  short VARCHAR(50) NOT NULL,
+  variant VARCHAR(32) NOT NULL DEFAULT '',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_url_clicks_short ON url_clicks(short);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE url_clicks;
+-- +goose StatementEnd
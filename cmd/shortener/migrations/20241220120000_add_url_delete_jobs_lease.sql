@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE url_delete_jobs
+  ADD COLUMN owner VARCHAR(64) NOT NULL DEFAULT '',
+  ADD COLUMN lease_until TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+DROP INDEX idx_url_delete_jobs_pending;
+
+CREATE INDEX idx_url_delete_jobs_pending
+ON url_delete_jobs(lease_until)
+WHERE status IN ('queued', 'running');
+
+CREATE INDEX idx_url_delete_jobs_owner
+ON url_delete_jobs(owner)
+WHERE status IN ('queued', 'running');
+
+CREATE INDEX idx_url_delete_jobs_finished
+ON url_delete_jobs(updated_at)
+WHERE status IN ('succeeded', 'failed');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX idx_url_delete_jobs_finished;
+DROP INDEX idx_url_delete_jobs_owner;
+DROP INDEX idx_url_delete_jobs_pending;
+
+ALTER TABLE url_delete_jobs
+  DROP COLUMN lease_until,
+  DROP COLUMN owner;
+
+CREATE INDEX idx_url_delete_jobs_pending
+ON url_delete_jobs(updated_at)
+WHERE status IN ('queued', 'running');
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+DROP INDEX idx_urls_url;
+
+CREATE UNIQUE INDEX idx_urls_url
+ON urls(url)
+WHERE NOT is_deleted;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX idx_urls_url;
+
+CREATE UNIQUE INDEX idx_urls_url
+ON urls(url);
+-- +goose StatementEnd
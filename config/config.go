@@ -4,27 +4,63 @@ import (
 	"encoding/json"
 	"flag"
 	"os"
+	"time"
 
 	"github.com/caarlos0/env"
 )
 
 const (
-	_defaultAddr            = ":8080"
-	_defaultBaseAddr        = "http://localhost:8080"
-	_defaultFileStoragePath = "./urls.backup"
-	_defaultJWTSecret       = "secret"
+	_defaultAddr                  = ":8080"
+	_defaultBaseAddr              = "http://localhost:8080"
+	_defaultFileStoragePath       = "./urls.backup"
+	_defaultJWTSecret             = "secret"
+	_defaultDeleteQueueSize       = 64
+	_defaultDeleteJobsStoragePath = "./delete_jobs.journal"
+	_defaultDeleteBatchWindow     = 10 * time.Millisecond
+	_defaultDeleteBatchMaxHashes  = 1000
+	_defaultDeleteWorkersMin      = 4
+	_defaultDeleteWorkersMax      = 16
+	_defaultURLCacheSize          = 10000
+	_defaultURLCacheTTL           = 30 * time.Second
+	_defaultURLCacheNegativeTTL   = 30 * time.Second
+	_defaultDBMaxConns            = 10
+	_defaultDBMaxConnIdleTime     = 30 * time.Minute
+	_defaultDBMaxConnLifetime     = time.Hour
+	_defaultRedirectCode          = 307
+	_defaultRedirectCacheMaxAge   = 24 * time.Hour
+	_defaultNotActiveCode         = 404
 )
 
 // Config конфигурация приложения.
 type Config struct {
-	Addr            string     `env:"SERVER_ADDRESS"    json:"server_address"`
-	BaseAddr        string     `env:"BASE_URL"          json:"base_url"`
-	FileStoragePath string     `env:"FILE_STORAGE_PATH" json:"file_storage_path"`
-	DatabaseDsn     string     `env:"DATABASE_DSN"      json:"database_dsn"`
-	HTTPSEnabled    bool       `env:"ENABLE_HTTPS"      json:"enable_https"`
-	JWTSecret       string     `env:"JWT_SECRET"        json:"-"`
-	AppEnv          string     `env:"APP_ENV"           json:"-"`
-	Meta            configMeta `json:"-"`
+	Addr                  string     `env:"SERVER_ADDRESS"           json:"server_address"`
+	BaseAddr              string     `env:"BASE_URL"                 json:"base_url"`
+	FileStoragePath       string     `env:"FILE_STORAGE_PATH"        json:"file_storage_path"`
+	DatabaseDsn           string     `env:"DATABASE_DSN"             json:"database_dsn"`
+	DatabaseReplicaDsn    string     `env:"DATABASE_REPLICA_DSN"     json:"database_replica_dsn"`
+	DBMaxConns            int        `env:"DB_MAX_CONNS"             json:"db_max_conns"`
+	DBMaxConnIdleTime     Duration   `env:"DB_MAX_CONN_IDLE_TIME"    json:"db_max_conn_idle_time"`
+	DBMaxConnLifetime     Duration   `env:"DB_MAX_CONN_LIFETIME"     json:"db_max_conn_lifetime"`
+	HTTPSEnabled          bool       `env:"ENABLE_HTTPS"             json:"enable_https"`
+	JWTSecret             string     `env:"JWT_SECRET"               json:"-"`
+	AppEnv                string     `env:"APP_ENV"                  json:"-"`
+	DeleteQueueSize       int        `env:"DELETE_QUEUE_SIZE"        json:"delete_queue_size"`
+	DeleteJobsStoragePath string     `env:"DELETE_JOBS_STORAGE_PATH" json:"delete_jobs_storage_path"`
+	DeleteBatchWindow     Duration   `env:"DELETE_BATCH_WINDOW"      json:"delete_batch_window"`
+	DeleteBatchMaxHashes  int        `env:"DELETE_BATCH_MAX_HASHES"  json:"delete_batch_max_hashes"`
+	DeleteWorkersMin      int        `env:"DELETE_WORKERS_MIN"       json:"delete_workers_min"`
+	DeleteWorkersMax      int        `env:"DELETE_WORKERS_MAX"       json:"delete_workers_max"`
+	URLCacheSize          int        `env:"URL_CACHE_SIZE"           json:"url_cache_size"`
+	URLCacheTTL           Duration   `env:"URL_CACHE_TTL"            json:"url_cache_ttl"`
+	URLCacheNegativeTTL   Duration   `env:"URL_CACHE_NEGATIVE_TTL"   json:"url_cache_negative_ttl"`
+	DefaultRedirectCode   int        `env:"DEFAULT_REDIRECT_CODE"    json:"default_redirect_code"`
+	RedirectCacheMaxAge   Duration   `env:"REDIRECT_CACHE_MAX_AGE"   json:"redirect_cache_max_age"`
+	NotActiveCode         int        `env:"NOT_ACTIVE_CODE"          json:"not_active_code"`
+	GeoIPDatabasePath     string     `env:"GEOIP_DATABASE_PATH"      json:"geoip_database_path"`
+	TemplatesDir          string     `env:"TEMPLATES_DIR"            json:"templates_dir"`
+	TrustedProxies        string     `env:"TRUSTED_PROXIES"          json:"trusted_proxies"`
+	TrustedSubnet         string     `env:"TRUSTED_SUBNET"           json:"trusted_subnet"`
+	Meta                  configMeta `json:"-"`
 }
 
 type configMeta struct {
@@ -33,10 +69,25 @@ type configMeta struct {
 
 func newDefaultConfig() *Config {
 	return &Config{
-		Addr:            _defaultAddr,
-		BaseAddr:        _defaultBaseAddr,
-		FileStoragePath: _defaultFileStoragePath,
-		JWTSecret:       _defaultJWTSecret,
+		Addr:                  _defaultAddr,
+		BaseAddr:              _defaultBaseAddr,
+		FileStoragePath:       _defaultFileStoragePath,
+		JWTSecret:             _defaultJWTSecret,
+		DeleteQueueSize:       _defaultDeleteQueueSize,
+		DeleteJobsStoragePath: _defaultDeleteJobsStoragePath,
+		DeleteBatchWindow:     Duration{_defaultDeleteBatchWindow},
+		DeleteBatchMaxHashes:  _defaultDeleteBatchMaxHashes,
+		DeleteWorkersMin:      _defaultDeleteWorkersMin,
+		DeleteWorkersMax:      _defaultDeleteWorkersMax,
+		URLCacheSize:          _defaultURLCacheSize,
+		URLCacheTTL:           Duration{_defaultURLCacheTTL},
+		URLCacheNegativeTTL:   Duration{_defaultURLCacheNegativeTTL},
+		DBMaxConns:            _defaultDBMaxConns,
+		DBMaxConnIdleTime:     Duration{_defaultDBMaxConnIdleTime},
+		DBMaxConnLifetime:     Duration{_defaultDBMaxConnLifetime},
+		DefaultRedirectCode:   _defaultRedirectCode,
+		RedirectCacheMaxAge:   Duration{_defaultRedirectCacheMaxAge},
+		NotActiveCode:         _defaultNotActiveCode,
 	}
 }
 
@@ -104,6 +155,22 @@ func (cfg *Config) merge(target *Config) {
 		cfg.DatabaseDsn = target.DatabaseDsn
 	}
 
+	if len(target.DatabaseReplicaDsn) != 0 {
+		cfg.DatabaseReplicaDsn = target.DatabaseReplicaDsn
+	}
+
+	if target.DBMaxConns != 0 {
+		cfg.DBMaxConns = target.DBMaxConns
+	}
+
+	if target.DBMaxConnIdleTime.Duration != 0 {
+		cfg.DBMaxConnIdleTime = target.DBMaxConnIdleTime
+	}
+
+	if target.DBMaxConnLifetime.Duration != 0 {
+		cfg.DBMaxConnLifetime = target.DBMaxConnLifetime
+	}
+
 	if target.HTTPSEnabled {
 		cfg.HTTPSEnabled = target.HTTPSEnabled
 	}
@@ -116,6 +183,70 @@ func (cfg *Config) merge(target *Config) {
 		cfg.AppEnv = target.AppEnv
 	}
 
+	if target.DeleteQueueSize != 0 {
+		cfg.DeleteQueueSize = target.DeleteQueueSize
+	}
+
+	if len(target.DeleteJobsStoragePath) != 0 {
+		cfg.DeleteJobsStoragePath = target.DeleteJobsStoragePath
+	}
+
+	if target.DeleteBatchWindow.Duration != 0 {
+		cfg.DeleteBatchWindow = target.DeleteBatchWindow
+	}
+
+	if target.DeleteBatchMaxHashes != 0 {
+		cfg.DeleteBatchMaxHashes = target.DeleteBatchMaxHashes
+	}
+
+	if target.DeleteWorkersMin != 0 {
+		cfg.DeleteWorkersMin = target.DeleteWorkersMin
+	}
+
+	if target.DeleteWorkersMax != 0 {
+		cfg.DeleteWorkersMax = target.DeleteWorkersMax
+	}
+
+	if target.URLCacheSize != 0 {
+		cfg.URLCacheSize = target.URLCacheSize
+	}
+
+	if target.URLCacheTTL.Duration != 0 {
+		cfg.URLCacheTTL = target.URLCacheTTL
+	}
+
+	if target.URLCacheNegativeTTL.Duration != 0 {
+		cfg.URLCacheNegativeTTL = target.URLCacheNegativeTTL
+	}
+
+	if target.DefaultRedirectCode != 0 {
+		cfg.DefaultRedirectCode = target.DefaultRedirectCode
+	}
+
+	if target.RedirectCacheMaxAge.Duration != 0 {
+		cfg.RedirectCacheMaxAge = target.RedirectCacheMaxAge
+	}
+
+	if target.NotActiveCode != 0 {
+		cfg.NotActiveCode = target.NotActiveCode
+	}
+
+	if len(target.GeoIPDatabasePath) != 0 {
+		cfg.GeoIPDatabasePath = target.GeoIPDatabasePath
+	}
+
+	if len(target.TemplatesDir) != 0 {
+		cfg.TemplatesDir = target.TemplatesDir
+	}
+
+	if len(target.TrustedProxies) != 0 {
+		cfg.TrustedProxies = target.TrustedProxies
+	}
+
+	if len(target.TrustedSubnet) != 0 {
+		cfg.TrustedSubnet = target.TrustedSubnet
+	}
+
 	if len(target.Meta.SRC) != 0 {
 		cfg.Meta.SRC = target.Meta.SRC
 	}
@@ -0,0 +1,26 @@
+package config
+
+import "time"
+
+// Duration интервал времени, который в переменных окружения и файле конфигурации
+// задается строкой в формате time.ParseDuration, например "10ms".
+type Duration struct {
+	time.Duration
+}
+
+// UnmarshalText разбирает интервал из строки.
+func (d *Duration) UnmarshalText(text []byte) error {
+	duration, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+
+	d.Duration = duration
+
+	return nil
+}
+
+// MarshalText сериализует интервал в строку.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
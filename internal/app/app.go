@@ -1,6 +1,9 @@
 package app
 
 import (
+	"expvar"
+	"html/template"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -36,14 +39,18 @@ type Option func(app *App)
 
 // App приложение.
 type App struct {
-	urlUseCase    *usecase.URLUseCase
-	healthUseCase *usecase.HealthUseCase
-	router        chi.Router
-	log           *zerolog.Logger
-	addr          string
-	jwtSecret     string
-	isDebug       bool
-	httpsEnabled  bool
+	urlUseCase     *usecase.URLUseCase
+	healthUseCase  *usecase.HealthUseCase
+	router         chi.Router
+	log            *zerolog.Logger
+	templates      *template.Template
+	metrics        expvar.Var
+	trustedProxies []*net.IPNet
+	trustedSubnet  *net.IPNet
+	addr           string
+	jwtSecret      string
+	isDebug        bool
+	httpsEnabled   bool
 }
 
 // Addr устанавливает адрес, на котором будет запускаться http сервер.
@@ -74,6 +81,36 @@ func HTTPSEnabled(enabled bool) Option {
 	}
 }
 
+// Templates устанавливает шаблоны HTML страниц.
+func Templates(templates *template.Template) Option {
+	return func(app *App) {
+		app.templates = templates
+	}
+}
+
+// TrustedProxies задает прокси, которым разрешено передавать адрес клиента
+// в X-Forwarded-For и X-Real-IP.
+func TrustedProxies(proxies []*net.IPNet) Option {
+	return func(app *App) {
+		app.trustedProxies = proxies
+	}
+}
+
+// Metrics публикует метрики приложения на /metrics независимо от режима отладки.
+func Metrics(metrics expvar.Var) Option {
+	return func(app *App) {
+		app.metrics = metrics
+	}
+}
+
+// TrustedSubnet задает подсеть, из которой доступны внутренние роуты, например /metrics.
+// Без подсети внутренние роуты закрыты.
+func TrustedSubnet(subnet *net.IPNet) Option {
+	return func(app *App) {
+		app.trustedSubnet = subnet
+	}
+}
+
 // New создает инстанс приложения.
 func New(
 	urlUseCase *usecase.URLUseCase,
@@ -99,12 +136,26 @@ func New(
 func (app *App) Run() {
 	auth := middleware.NewAuth(app.jwtSecret, app.log)
 	healthRoutes := rest.NewHealthRoutes(app.healthUseCase, app.log)
-	urlRoutes := rest.NewURLRoutes(app.urlUseCase, auth, app.log)
+
+	var urlRoutesOpts []rest.URLRoutesOption
+	if app.templates != nil {
+		urlRoutesOpts = append(urlRoutesOpts, rest.Templates(app.templates))
+	}
+
+	urlRoutes := rest.NewURLRoutes(app.urlUseCase, auth, app.log, urlRoutesOpts...)
+
+	if len(app.trustedProxies) > 0 {
+		app.router.Use(middleware.RealIP(app.trustedProxies))
+	}
 
 	app.router.Use(middleware.LoggerMiddleware(app.log))
 	healthRoutes.Apply(app.router)
 	urlRoutes.Apply(app.router)
 
+	if app.metrics != nil {
+		rest.NewMetricsRoutes(app.metrics, app.log).Apply(app.router.With(middleware.TrustedSubnet(app.trustedSubnet)))
+	}
+
 	if app.isDebug {
 		app.router.Mount("/debug", chiMiddleware.Profiler())
 	}
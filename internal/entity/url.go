@@ -1,12 +1,99 @@
 package entity
 
+import (
+	"net/http"
+	"time"
+)
+
+// URLQueryPolicy правило переноса параметров запроса в урл назначения.
+type URLQueryPolicy string
+
+const (
+	// URLQueryDrop параметры запроса отбрасываются.
+	URLQueryDrop URLQueryPolicy = "drop"
+	// URLQueryKeepExisting добавляются только параметры, которых нет в урле назначения.
+	URLQueryKeepExisting URLQueryPolicy = "keep-existing"
+	// URLQueryOverride параметры запроса заменяют одноименные параметры урла назначения.
+	URLQueryOverride URLQueryPolicy = "override"
+)
+
+// UTMParams допустимые ключи UTM меток.
+var UTMParams = []string{"utm_source", "utm_medium", "utm_campaign", "utm_term", "utm_content"}
+
+// URLSettings настройки короткой ссылки, которые задаются при создании.
+type URLSettings struct {
+	// Rules правила выбора урла назначения, проверяются по порядку до первого совпадения.
+	Rules []URLRule `json:"rules,omitempty"`
+	// Variants варианты урла назначения для A/B теста, используются, если не сработало ни одно правило.
+	Variants []URLVariant `json:"variants,omitempty"`
+	// UTM метки, которые добавляются к урлу назначения, если в нем их еще нет.
+	UTM map[string]string `json:"utm,omitempty"`
+	// QueryPolicy правило переноса параметров запроса, пустое значение означает URLQueryDrop.
+	QueryPolicy URLQueryPolicy `json:"query_policy,omitempty"`
+	// RedirectCode код ответа при переходе по ссылке, 0 означает код по умолчанию.
+	RedirectCode int `json:"redirect_code,omitempty"`
+	// PasswordHash bcrypt хэш пароля, без которого ссылка не открывается.
+	PasswordHash string `json:"password_hash,omitempty"`
+	// Title заголовок ссылки, который владелец показывает на странице предпросмотра.
+	Title string `json:"title,omitempty"`
+	// ActiveFrom время, до которого ссылка сохранена, но еще не открывается.
+	ActiveFrom *time.Time `json:"active_from,omitempty"`
+	// ExpiresAt время, после которого ссылка перестает работать, nil означает бессрочную ссылку.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// Disabled ссылка сохранена, но не открывается.
+	Disabled bool `json:"disabled,omitempty"`
+	// Interstitial вместо редиректа всегда показывает страницу предпросмотра.
+	Interstitial bool `json:"interstitial,omitempty"`
+	// StickyVariant закрепляет за посетителем первый показанный ему вариант.
+	StickyVariant bool `json:"sticky_variant,omitempty"`
+}
+
 // URL содержит данные о сокращенном урле.
 type URL struct {
-	UUID     string `json:"uuid"`
-	Short    string `json:"short_url"`
-	Original string `json:"original_url"`
-	UserUUID string `json:"user_uuid"`
-	Deleted  bool   `json:"is_deleted"`
+	CreatedAt time.Time `json:"created_at"`
+	UUID      string    `json:"uuid"`
+	Short     string    `json:"short_url"`
+	Original  string    `json:"original_url"`
+	UserUUID  string    `json:"user_uuid"`
+	Deleted   bool      `json:"is_deleted"`
+	URLSettings
+}
+
+// HasPassword проверяет, что ссылка защищена паролем.
+func (url *URL) HasPassword() bool {
+	return url.PasswordHash != ""
+}
+
+// IsActive проверяет, что к моменту now ссылка уже начала работать.
+func (url *URL) IsActive(now time.Time) bool {
+	return url.ActiveFrom == nil || !now.Before(*url.ActiveFrom)
+}
+
+// IsExpired проверяет, что срок действия ссылки истек к моменту now.
+func (url *URL) IsExpired(now time.Time) bool {
+	return url.ExpiresAt != nil && !now.Before(*url.ExpiresAt)
+}
+
+// URLDraft данные для создания короткой ссылки.
+type URLDraft struct {
+	Original string
+	// Password пароль ссылки в открытом виде, сохраняется только его хэш.
+	Password string
+	URLSettings
+}
+
+// IsRedirectCode проверяет, что код подходит для редиректа по короткой ссылке.
+func IsRedirectCode(code int) bool {
+	switch code {
+	case http.StatusMovedPermanently,
+		http.StatusFound,
+		http.StatusSeeOther,
+		http.StatusTemporaryRedirect,
+		http.StatusPermanentRedirect:
+		return true
+	default:
+		return false
+	}
 }
 
 // URLDeleteItem dto удаление урлов.
@@ -14,3 +101,25 @@ type URLDeleteItem struct {
 	UserUUID string
 	Hashes   []string
 }
+
+// URLStoreStatus итог сохранения урла из пачки.
+type URLStoreStatus string
+
+const (
+	// URLStoreCreated урл сохранен.
+	URLStoreCreated URLStoreStatus = "created"
+	// URLStoreDuplicate урл уже был сокращен раньше.
+	URLStoreDuplicate URLStoreStatus = "duplicate"
+	// URLStoreInvalid урл не прошел проверку.
+	URLStoreInvalid URLStoreStatus = "invalid"
+	// URLStoreSkipped урл корректен, но не сохранен, потому что пачка отклонена целиком.
+	URLStoreSkipped URLStoreStatus = "skipped"
+)
+
+// URLStoreResult результат сохранения урла из пачки.
+// Для дубля URL содержит ранее сохраненный урл, для невалидного урла Reason содержит причину.
+type URLStoreResult struct {
+	URL    *URL
+	Status URLStoreStatus
+	Reason string
+}
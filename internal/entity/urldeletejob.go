@@ -0,0 +1,50 @@
+package entity
+
+import "time"
+
+// URLDeleteJobStatus статус задачи удаления урлов.
+type URLDeleteJobStatus string
+
+// Возможные статусы задачи удаления урлов.
+const (
+	URLDeleteJobQueued    URLDeleteJobStatus = "queued"
+	URLDeleteJobRunning   URLDeleteJobStatus = "running"
+	URLDeleteJobSucceeded URLDeleteJobStatus = "succeeded"
+	URLDeleteJobFailed    URLDeleteJobStatus = "failed"
+)
+
+// URLDeleteStatus результат удаления конкретного урла.
+type URLDeleteStatus string
+
+// Возможные результаты удаления урла.
+const (
+	URLDeleteDone     URLDeleteStatus = "deleted"
+	URLDeleteNotFound URLDeleteStatus = "not-found"
+	URLDeleteNotOwned URLDeleteStatus = "not-owned"
+)
+
+// URLDeleteResult результат удаления урла по хэшу.
+type URLDeleteResult struct {
+	Hash   string          `json:"hash"`
+	Status URLDeleteStatus `json:"status"`
+}
+
+// URLDeleteJob задача удаления урлов, поставленная в очередь.
+// Owner - инстанс, который выполняет задачу; пока LeaseUntil не истек, другие инстансы ее не забирают.
+type URLDeleteJob struct {
+	CreatedAt  time.Time          `json:"created_at"`
+	UpdatedAt  time.Time          `json:"updated_at"`
+	LeaseUntil time.Time          `json:"lease_until"`
+	ID         string             `json:"id"`
+	UserUUID   string             `json:"user_uuid"`
+	Owner      string             `json:"owner,omitempty"`
+	Status     URLDeleteJobStatus `json:"status"`
+	Error      string             `json:"error,omitempty"`
+	Hashes     []string           `json:"hashes"`
+	Results    []*URLDeleteResult `json:"results"`
+}
+
+// IsFinished сообщает, завершилась ли задача.
+func (job *URLDeleteJob) IsFinished() bool {
+	return job.Status == URLDeleteJobSucceeded || job.Status == URLDeleteJobFailed
+}
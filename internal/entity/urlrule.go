@@ -0,0 +1,93 @@
+package entity
+
+import (
+	"encoding/json"
+	"net"
+	"net/url"
+	"time"
+)
+
+// Платформы посетителя, определяемые по User-Agent.
+const (
+	PlatformIOS     = "ios"
+	PlatformAndroid = "android"
+	PlatformWindows = "windows"
+	PlatformMacOS   = "macos"
+	PlatformLinux   = "linux"
+	PlatformOther   = "other"
+)
+
+// Platforms допустимые платформы в правилах редиректа.
+var Platforms = []string{
+	PlatformIOS,
+	PlatformAndroid,
+	PlatformWindows,
+	PlatformMacOS,
+	PlatformLinux,
+	PlatformOther,
+}
+
+// URLTimeWindow ежедневный интервал времени в формате ЧЧ:ММ. Если From позже To,
+// интервал переходит через полночь. Пустой часовой пояс означает UTC.
+type URLTimeWindow struct {
+	location *time.Location
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// ResolveLocation загружает часовой пояс и запоминает его, чтобы не искать пояс при каждом переходе.
+func (w *URLTimeWindow) ResolveLocation() error {
+	location, err := time.LoadLocation(w.Timezone)
+	if err != nil {
+		return err
+	}
+
+	w.location = location
+
+	return nil
+}
+
+// Location возвращает запомненный часовой пояс или nil, если пояс еще не загружен.
+func (w *URLTimeWindow) Location() *time.Location {
+	return w.location
+}
+
+// UnmarshalJSON разбирает интервал и сразу загружает его часовой пояс.
+func (w *URLTimeWindow) UnmarshalJSON(data []byte) error {
+	type plain URLTimeWindow
+
+	var window plain
+
+	if err := json.Unmarshal(data, &window); err != nil {
+		return err
+	}
+
+	*w = URLTimeWindow(window)
+
+	// неизвестный пояс не ошибка разбора, его отклонит проверка настроек
+	_ = w.ResolveLocation()
+
+	return nil
+}
+
+// URLRule правило выбора урла назначения. Правило срабатывает, если выполнены все заданные условия,
+// пустое условие выполняется всегда.
+type URLRule struct {
+	TimeWindow  *URLTimeWindow `json:"time_window,omitempty"`
+	Destination string         `json:"destination"`
+	Platforms   []string       `json:"platforms,omitempty"`
+	Languages   []string       `json:"languages,omitempty"`
+	Countries   []string       `json:"countries,omitempty"`
+}
+
+// Visit данные перехода по короткой ссылке.
+type Visit struct {
+	Time           time.Time
+	IP             net.IP
+	Query          url.Values
+	UserAgent      string
+	AcceptLanguage string
+	// Variant вариант, показанный посетителю ранее.
+	Variant string
+}
@@ -0,0 +1,50 @@
+package entity
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestURLTimeWindowUnmarshalJSON(t *testing.T) {
+	testCases := []struct {
+		name             string
+		data             string
+		expectedLocation string
+	}{
+		{
+			name:             "Resolves timezone",
+			data:             `{"from":"22:00","to":"06:00","timezone":"Europe/Moscow"}`,
+			expectedLocation: "Europe/Moscow",
+		},
+		{
+			name:             "Resolves empty timezone as utc",
+			data:             `{"from":"22:00","to":"06:00"}`,
+			expectedLocation: "UTC",
+		},
+		{
+			name: "Leaves unknown timezone unresolved",
+			data: `{"from":"22:00","to":"06:00","timezone":"Mars/Olympus"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var window URLTimeWindow
+
+			require.NoError(t, json.Unmarshal([]byte(tc.data), &window))
+			assert.Equal(t, "22:00", window.From)
+
+			if tc.expectedLocation == "" {
+				assert.Nil(t, window.Location())
+
+				return
+			}
+
+			require.NotNil(t, window.Location())
+			assert.Equal(t, tc.expectedLocation, window.Location().String())
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package entity
+
+import "time"
+
+// URLVariant вариант урла назначения для A/B теста.
+// Вариант выбирается случайно пропорционально весу.
+type URLVariant struct {
+	Name        string `json:"name"`
+	Destination string `json:"destination"`
+	Weight      int    `json:"weight"`
+}
+
+// Destination выбранный для перехода урл назначения.
+type Destination struct {
+	URL string
+	// Variant имя показанного варианта, пустое, если урл выбран не из вариантов.
+	Variant string
+}
+
+// URLClick переход по короткой ссылке.
+type URLClick struct {
+	Time    time.Time
+	Short   string
+	Variant string
+}
+
+// URLClickStats сводка переходов по короткой ссылке.
+type URLClickStats struct {
+	Variants map[string]int64 `json:"variants"`
+	Total    int64            `json:"total"`
+}
@@ -5,6 +5,7 @@
 package mocks
 
 import (
+	context "context"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -34,16 +35,30 @@ func (m *MockURLDeleteWorkerPool) EXPECT() *MockURLDeleteWorkerPoolMockRecorder
 	return m.recorder
 }
 
-// QueueWork mocks base method.
-func (m *MockURLDeleteWorkerPool) QueueWork(arg0 *usecase.URLDeleteWork) error {
+// QueueWorkContext mocks base method.
+func (m *MockURLDeleteWorkerPool) QueueWorkContext(arg0 context.Context, arg1 *usecase.URLDeleteWork) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "QueueWork", arg0)
+	ret := m.ctrl.Call(m, "QueueWorkContext", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// QueueWork indicates an expected call of QueueWork.
-func (mr *MockURLDeleteWorkerPoolMockRecorder) QueueWork(arg0 interface{}) *gomock.Call {
+// QueueWorkContext indicates an expected call of QueueWorkContext.
+func (mr *MockURLDeleteWorkerPoolMockRecorder) QueueWorkContext(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueueWork", reflect.TypeOf((*MockURLDeleteWorkerPool)(nil).QueueWork), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueueWorkContext", reflect.TypeOf((*MockURLDeleteWorkerPool)(nil).QueueWorkContext), arg0, arg1)
+}
+
+// TryQueueWork mocks base method.
+func (m *MockURLDeleteWorkerPool) TryQueueWork(arg0 *usecase.URLDeleteWork) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TryQueueWork", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TryQueueWork indicates an expected call of TryQueueWork.
+func (mr *MockURLDeleteWorkerPoolMockRecorder) TryQueueWork(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TryQueueWork", reflect.TypeOf((*MockURLDeleteWorkerPool)(nil).TryQueueWork), arg0)
 }
@@ -1,12 +1,14 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: github.com/llravell/go-shortener/internal/usecase (interfaces: URLRepo,HealthRepo,HashGenerator)
+// Source: github.com/llravell/go-shortener/internal/usecase (interfaces: URLRepo,URLDeleteJobRepo,ClickRepo,HealthRepo,HashGenerator,GeoLocator)
 
 // Package mocks is a generated GoMock package.
 package mocks
 
 import (
 	context "context"
+	net "net"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	entity "github.com/llravell/go-shortener/internal/entity"
@@ -36,11 +38,12 @@ func (m *MockURLRepo) EXPECT() *MockURLRepoMockRecorder {
 }
 
 // DeleteMultipleURLs mocks base method.
-func (m *MockURLRepo) DeleteMultipleURLs(arg0 context.Context, arg1 string, arg2 []string) error {
+func (m *MockURLRepo) DeleteMultipleURLs(arg0 context.Context, arg1 string, arg2 []string) ([]*entity.URLDeleteResult, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "DeleteMultipleURLs", arg0, arg1, arg2)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].([]*entity.URLDeleteResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // DeleteMultipleURLs indicates an expected call of DeleteMultipleURLs.
@@ -64,6 +67,21 @@ func (mr *MockURLRepoMockRecorder) GetURL(arg0, arg1 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetURL", reflect.TypeOf((*MockURLRepo)(nil).GetURL), arg0, arg1)
 }
 
+// GetUserScheduledURLs mocks base method.
+func (m *MockURLRepo) GetUserScheduledURLs(arg0 context.Context, arg1 string, arg2 time.Time) ([]*entity.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserScheduledURLs", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*entity.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserScheduledURLs indicates an expected call of GetUserScheduledURLs.
+func (mr *MockURLRepoMockRecorder) GetUserScheduledURLs(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserScheduledURLs", reflect.TypeOf((*MockURLRepo)(nil).GetUserScheduledURLs), arg0, arg1, arg2)
+}
+
 // GetUserURLS mocks base method.
 func (m *MockURLRepo) GetUserURLS(arg0 context.Context, arg1 string) ([]*entity.URL, error) {
 	m.ctrl.T.Helper()
@@ -79,6 +97,34 @@ func (mr *MockURLRepoMockRecorder) GetUserURLS(arg0, arg1 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserURLS", reflect.TypeOf((*MockURLRepo)(nil).GetUserURLS), arg0, arg1)
 }
 
+// IterateUserURLs mocks base method.
+func (m *MockURLRepo) IterateUserURLs(arg0 context.Context, arg1 string, arg2 func(*entity.URL) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IterateUserURLs", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IterateUserURLs indicates an expected call of IterateUserURLs.
+func (mr *MockURLRepoMockRecorder) IterateUserURLs(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IterateUserURLs", reflect.TypeOf((*MockURLRepo)(nil).IterateUserURLs), arg0, arg1, arg2)
+}
+
+// SetURLDisabled mocks base method.
+func (m *MockURLRepo) SetURLDisabled(arg0 context.Context, arg1, arg2 string, arg3 bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetURLDisabled", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetURLDisabled indicates an expected call of SetURLDisabled.
+func (mr *MockURLRepoMockRecorder) SetURLDisabled(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetURLDisabled", reflect.TypeOf((*MockURLRepo)(nil).SetURLDisabled), arg0, arg1, arg2, arg3)
+}
+
 // Store mocks base method.
 func (m *MockURLRepo) Store(arg0 context.Context, arg1 *entity.URL) (*entity.URL, error) {
 	m.ctrl.T.Helper()
@@ -95,11 +141,12 @@ func (mr *MockURLRepoMockRecorder) Store(arg0, arg1 interface{}) *gomock.Call {
 }
 
 // StoreMultipleURLs mocks base method.
-func (m *MockURLRepo) StoreMultipleURLs(arg0 context.Context, arg1 []*entity.URL) error {
+func (m *MockURLRepo) StoreMultipleURLs(arg0 context.Context, arg1 []*entity.URL) ([]*entity.URLStoreResult, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "StoreMultipleURLs", arg0, arg1)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].([]*entity.URLStoreResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // StoreMultipleURLs indicates an expected call of StoreMultipleURLs.
@@ -108,6 +155,168 @@ func (mr *MockURLRepoMockRecorder) StoreMultipleURLs(arg0, arg1 interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StoreMultipleURLs", reflect.TypeOf((*MockURLRepo)(nil).StoreMultipleURLs), arg0, arg1)
 }
 
+// MockURLDeleteJobRepo is a mock of URLDeleteJobRepo interface.
+type MockURLDeleteJobRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockURLDeleteJobRepoMockRecorder
+}
+
+// MockURLDeleteJobRepoMockRecorder is the mock recorder for MockURLDeleteJobRepo.
+type MockURLDeleteJobRepoMockRecorder struct {
+	mock *MockURLDeleteJobRepo
+}
+
+// NewMockURLDeleteJobRepo creates a new mock instance.
+func NewMockURLDeleteJobRepo(ctrl *gomock.Controller) *MockURLDeleteJobRepo {
+	mock := &MockURLDeleteJobRepo{ctrl: ctrl}
+	mock.recorder = &MockURLDeleteJobRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockURLDeleteJobRepo) EXPECT() *MockURLDeleteJobRepoMockRecorder {
+	return m.recorder
+}
+
+// ClaimExpired mocks base method.
+func (m *MockURLDeleteJobRepo) ClaimExpired(arg0 context.Context, arg1 string, arg2 time.Duration, arg3 int) ([]*entity.URLDeleteJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimExpired", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]*entity.URLDeleteJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimExpired indicates an expected call of ClaimExpired.
+func (mr *MockURLDeleteJobRepoMockRecorder) ClaimExpired(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimExpired", reflect.TypeOf((*MockURLDeleteJobRepo)(nil).ClaimExpired), arg0, arg1, arg2, arg3)
+}
+
+// DeleteFinished mocks base method.
+func (m *MockURLDeleteJobRepo) DeleteFinished(arg0 context.Context, arg1 time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFinished", arg0, arg1)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteFinished indicates an expected call of DeleteFinished.
+func (mr *MockURLDeleteJobRepoMockRecorder) DeleteFinished(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFinished", reflect.TypeOf((*MockURLDeleteJobRepo)(nil).DeleteFinished), arg0, arg1)
+}
+
+// ExtendLeases mocks base method.
+func (m *MockURLDeleteJobRepo) ExtendLeases(arg0 context.Context, arg1 string, arg2 time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExtendLeases", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExtendLeases indicates an expected call of ExtendLeases.
+func (mr *MockURLDeleteJobRepoMockRecorder) ExtendLeases(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExtendLeases", reflect.TypeOf((*MockURLDeleteJobRepo)(nil).ExtendLeases), arg0, arg1, arg2)
+}
+
+// Get mocks base method.
+func (m *MockURLDeleteJobRepo) Get(arg0 context.Context, arg1 string) (*entity.URLDeleteJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", arg0, arg1)
+	ret0, _ := ret[0].(*entity.URLDeleteJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockURLDeleteJobRepoMockRecorder) Get(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockURLDeleteJobRepo)(nil).Get), arg0, arg1)
+}
+
+// Store mocks base method.
+func (m *MockURLDeleteJobRepo) Store(arg0 context.Context, arg1 *entity.URLDeleteJob) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Store", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Store indicates an expected call of Store.
+func (mr *MockURLDeleteJobRepoMockRecorder) Store(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Store", reflect.TypeOf((*MockURLDeleteJobRepo)(nil).Store), arg0, arg1)
+}
+
+// Update mocks base method.
+func (m *MockURLDeleteJobRepo) Update(arg0 context.Context, arg1 *entity.URLDeleteJob) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockURLDeleteJobRepoMockRecorder) Update(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockURLDeleteJobRepo)(nil).Update), arg0, arg1)
+}
+
+// MockClickRepo is a mock of ClickRepo interface.
+type MockClickRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockClickRepoMockRecorder
+}
+
+// MockClickRepoMockRecorder is the mock recorder for MockClickRepo.
+type MockClickRepoMockRecorder struct {
+	mock *MockClickRepo
+}
+
+// NewMockClickRepo creates a new mock instance.
+func NewMockClickRepo(ctrl *gomock.Controller) *MockClickRepo {
+	mock := &MockClickRepo{ctrl: ctrl}
+	mock.recorder = &MockClickRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockClickRepo) EXPECT() *MockClickRepoMockRecorder {
+	return m.recorder
+}
+
+// GetClickStats mocks base method.
+func (m *MockClickRepo) GetClickStats(arg0 context.Context, arg1 string) (*entity.URLClickStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetClickStats", arg0, arg1)
+	ret0, _ := ret[0].(*entity.URLClickStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetClickStats indicates an expected call of GetClickStats.
+func (mr *MockClickRepoMockRecorder) GetClickStats(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClickStats", reflect.TypeOf((*MockClickRepo)(nil).GetClickStats), arg0, arg1)
+}
+
+// StoreClick mocks base method.
+func (m *MockClickRepo) StoreClick(arg0 context.Context, arg1 *entity.URLClick) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StoreClick", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StoreClick indicates an expected call of StoreClick.
+func (mr *MockClickRepoMockRecorder) StoreClick(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StoreClick", reflect.TypeOf((*MockClickRepo)(nil).StoreClick), arg0, arg1)
+}
+
 // MockHealthRepo is a mock of HealthRepo interface.
 type MockHealthRepo struct {
 	ctrl     *gomock.Controller
@@ -182,3 +391,41 @@ func (mr *MockHashGeneratorMockRecorder) Generate() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Generate", reflect.TypeOf((*MockHashGenerator)(nil).Generate))
 }
+
+// MockGeoLocator is a mock of GeoLocator interface.
+type MockGeoLocator struct {
+	ctrl     *gomock.Controller
+	recorder *MockGeoLocatorMockRecorder
+}
+
+// MockGeoLocatorMockRecorder is the mock recorder for MockGeoLocator.
+type MockGeoLocatorMockRecorder struct {
+	mock *MockGeoLocator
+}
+
+// NewMockGeoLocator creates a new mock instance.
+func NewMockGeoLocator(ctrl *gomock.Controller) *MockGeoLocator {
+	mock := &MockGeoLocator{ctrl: ctrl}
+	mock.recorder = &MockGeoLocatorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockGeoLocator) EXPECT() *MockGeoLocatorMockRecorder {
+	return m.recorder
+}
+
+// Country mocks base method.
+func (m *MockGeoLocator) Country(arg0 net.IP) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Country", arg0)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Country indicates an expected call of Country.
+func (mr *MockGeoLocatorMockRecorder) Country(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Country", reflect.TypeOf((*MockGeoLocator)(nil).Country), arg0)
+}
@@ -0,0 +1,43 @@
+package repo
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/pressly/goose/v3"
+	"github.com/stretchr/testify/require"
+)
+
+const testMigrationsDir = "../../cmd/shortener/migrations"
+
+// openTestDatabase подключается к базе из TEST_DATABASE_DSN, накатывает миграции и очищает таблицы.
+// Без переменной окружения тест пропускается.
+func openTestDatabase(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN is not set")
+	}
+
+	pool, err := pgxpool.New(context.Background(), dsn)
+	require.NoError(t, err)
+
+	db := stdlib.OpenDBFromPool(pool)
+
+	t.Cleanup(func() {
+		db.Close()
+		pool.Close()
+	})
+
+	require.NoError(t, goose.SetDialect("postgres"))
+	require.NoError(t, goose.Up(db, testMigrationsDir))
+
+	_, err = db.Exec(`TRUNCATE urls, url_delete_jobs, url_clicks;`)
+	require.NoError(t, err)
+
+	return pool
+}
@@ -0,0 +1,131 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const replicaPingTimeout = 5 * time.Second
+
+// replica подключение к реплике базы данных с признаком ее доступности.
+// Недоступная реплика исключается из чтения до следующей успешной проверки.
+type replica struct {
+	conn    *pgxpool.Pool
+	ping    func(ctx context.Context) error
+	healthy atomic.Bool
+}
+
+func newReplica(conn *pgxpool.Pool) *replica {
+	rep := &replica{conn: conn, ping: conn.Ping}
+	rep.healthy.Store(true)
+
+	return rep
+}
+
+// Классы ошибок PostgreSQL, означающие, что сервер недоступен, а не что запрос неверен:
+// 08 - проблемы соединения, 57P - остановка сервера, 53 - нехватка ресурсов.
+var connectionErrorClasses = []string{"08", "57P", "53"}
+
+// isConnectionError отличает отказ реплики от ожидаемых ошибок чтения, например,
+// отсутствия урла или ошибки в запросе.
+func isConnectionError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		for _, class := range connectionErrorClasses {
+			if strings.HasPrefix(pgErr.Code, class) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	var (
+		connectErr *pgconn.ConnectError
+		netErr     net.Error
+	)
+
+	return errors.As(err, &connectErr) ||
+		errors.As(err, &netErr) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		pgconn.SafeToRetry(err)
+}
+
+func (rep *replica) check(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, replicaPingTimeout)
+	defer cancel()
+
+	rep.healthy.Store(rep.ping(ctx) == nil)
+}
+
+// Replica направляет чтение урлов в реплику. Запись и проверки на дубли
+// по-прежнему выполняются на основной базе.
+func Replica(conn *pgxpool.Pool) URLDatabaseRepoOption {
+	return func(r *URLDatabaseRepo) {
+		r.replica = newReplica(conn)
+	}
+}
+
+// readConn возвращает подключение для чтения: реплику, если она доступна, иначе основную базу.
+func (r *URLDatabaseRepo) readConn() *pgxpool.Pool {
+	if r.replica != nil && r.replica.healthy.Load() {
+		return r.replica.conn
+	}
+
+	return r.pool
+}
+
+// readWithFallback выполняет чтение на реплике и повторяет его на основной базе,
+// если реплика недоступна или еще не получила нужные данные. Исключается из чтения
+// реплика только при ошибке соединения: обычный промах ее здоровье не меняет.
+func readWithFallback[T any](
+	ctx context.Context,
+	r *URLDatabaseRepo,
+	read func(conn *pgxpool.Pool) (T, error),
+	shouldFallback func(err error) bool,
+) (T, error) {
+	conn := r.readConn()
+
+	result, err := read(conn)
+	if conn == r.pool || !shouldFallback(err) {
+		return result, err
+	}
+
+	if isConnectionError(err) && ctx.Err() == nil {
+		r.replica.healthy.Store(false)
+	}
+
+	return read(r.pool)
+}
+
+// WatchReplica периодически проверяет доступность реплики, пока не будет отменен контекст.
+func (r *URLDatabaseRepo) WatchReplica(ctx context.Context, interval time.Duration) {
+	if r.replica == nil {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.replica.check(ctx)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/llravell/go-shortener/internal/entity"
+)
+
+// Порт 1 закрыт, поэтому подключение к нему сразу завершается ошибкой соединения.
+const unreachableDSN = "postgres://user@127.0.0.1:1/db?connect_timeout=1"
+
+func newUnreachablePool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+
+	pool, err := pgxpool.New(context.Background(), unreachableDSN)
+	require.NoError(t, err)
+
+	t.Cleanup(pool.Close)
+
+	return pool
+}
+
+func newTestReplicaRepo(t *testing.T) (*URLDatabaseRepo, *pgxpool.Pool) {
+	t.Helper()
+
+	replicaPool := newUnreachablePool(t)
+
+	return NewURLDatabaseRepo(newUnreachablePool(t), Replica(replicaPool)), replicaPool
+}
+
+func TestIsConnectionError(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{name: "no error", err: nil, expected: false},
+		{name: "url not found", err: NewURLNotFoundError("a"), expected: false},
+		{name: "no rows", err: pgx.ErrNoRows, expected: false},
+		{name: "canceled request", err: context.Canceled, expected: false},
+		{name: "query error", err: &pgconn.PgError{Code: "42703"}, expected: false},
+		{name: "unique violation", err: &pgconn.PgError{Code: "23505"}, expected: false},
+		{name: "connection failure", err: &pgconn.PgError{Code: "08006"}, expected: true},
+		{name: "server shutdown", err: &pgconn.PgError{Code: "57P01"}, expected: true},
+		{name: "too many connections", err: &pgconn.PgError{Code: "53300"}, expected: true},
+		{name: "network error", err: fmt.Errorf("read: %w", &net.OpError{Op: "read", Err: io.EOF}), expected: true},
+		{name: "unexpected eof", err: io.ErrUnexpectedEOF, expected: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, isConnectionError(tc.err))
+		})
+	}
+
+	t.Run("connect error", func(t *testing.T) {
+		err := newUnreachablePool(t).Ping(context.Background())
+
+		var connectErr *pgconn.ConnectError
+
+		require.ErrorAs(t, err, &connectErr)
+		assert.True(t, isConnectionError(err))
+	})
+}
+
+//nolint:funlen
+func TestReadWithFallback(t *testing.T) {
+	ctx := context.Background()
+	primaryURL := &entity.URL{Short: "a", Original: "https://a.ru"}
+	shouldFallback := isURLMissOrConnectionError
+
+	t.Run("Falls back on miss and keeps replica healthy", func(t *testing.T) {
+		r, replicaPool := newTestReplicaRepo(t)
+		replicaReads := 0
+
+		url, err := readWithFallback(ctx, r, func(conn *pgxpool.Pool) (*entity.URL, error) {
+			if conn == replicaPool {
+				replicaReads++
+
+				return nil, NewURLNotFoundError("a")
+			}
+
+			return primaryURL, nil
+		}, shouldFallback)
+		require.NoError(t, err)
+		assert.Equal(t, primaryURL, url)
+		assert.Equal(t, 1, replicaReads)
+		assert.True(t, r.replica.healthy.Load())
+		assert.Same(t, replicaPool, r.readConn())
+	})
+
+	t.Run("Falls back on connection error and marks replica down", func(t *testing.T) {
+		r, replicaPool := newTestReplicaRepo(t)
+
+		url, err := readWithFallback(ctx, r, func(conn *pgxpool.Pool) (*entity.URL, error) {
+			if conn == replicaPool {
+				return nil, conn.Ping(ctx)
+			}
+
+			return primaryURL, nil
+		}, shouldFallback)
+		require.NoError(t, err)
+		assert.Equal(t, primaryURL, url)
+		assert.False(t, r.replica.healthy.Load())
+		assert.Same(t, r.pool, r.readConn())
+	})
+
+	t.Run("Does not fall back on query error", func(t *testing.T) {
+		r, replicaPool := newTestReplicaRepo(t)
+		queryErr := &pgconn.PgError{Code: "42703"}
+
+		_, err := readWithFallback(ctx, r, func(conn *pgxpool.Pool) (*entity.URL, error) {
+			if conn == replicaPool {
+				return nil, queryErr
+			}
+
+			return primaryURL, nil
+		}, shouldFallback)
+		require.ErrorIs(t, err, queryErr)
+		assert.True(t, r.replica.healthy.Load())
+	})
+
+	t.Run("Health watcher returns recovered replica", func(t *testing.T) {
+		r, replicaPool := newTestReplicaRepo(t)
+		r.replica.healthy.Store(false)
+		r.replica.ping = func(context.Context) error { return nil }
+
+		watchCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		go r.WatchReplica(watchCtx, time.Millisecond)
+
+		require.Eventually(t, r.replica.healthy.Load, time.Second, time.Millisecond)
+		assert.Same(t, replicaPool, r.readConn())
+	})
+}
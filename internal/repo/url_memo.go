@@ -3,7 +3,10 @@ package repo
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/llravell/go-shortener/internal/entity"
 )
@@ -19,6 +22,11 @@ type URLNotFoundError struct {
 	hash string
 }
 
+// NewURLNotFoundError создает ошибку поиска урла по хэшу.
+func NewURLNotFoundError(hash string) *URLNotFoundError {
+	return &URLNotFoundError{hash}
+}
+
 // Error реализация интерфейса ошибки.
 func (err *URLNotFoundError) Error() string {
 	return fmt.Sprintf(`Not found url with hash "%s"`, err.hash)
@@ -33,6 +41,10 @@ func NewURLMemoRepo() *URLMemoRepo {
 
 // Store сохраняет урл.
 func (r *URLMemoRepo) Store(_ context.Context, url *entity.URL) (*entity.URL, error) {
+	if url.CreatedAt.IsZero() {
+		url.CreatedAt = time.Now()
+	}
+
 	r.mu.Lock()
 	r.m[url.Short] = url
 	r.mu.Unlock()
@@ -40,15 +52,23 @@ func (r *URLMemoRepo) Store(_ context.Context, url *entity.URL) (*entity.URL, er
 	return url, nil
 }
 
-// StoreMultipleURLs сохраняет несколько урлов.
-func (r *URLMemoRepo) StoreMultipleURLs(_ context.Context, urls []*entity.URL) error {
+// StoreMultipleURLs сохраняет несколько урлов, возвращает результат по каждому.
+func (r *URLMemoRepo) StoreMultipleURLs(_ context.Context, urls []*entity.URL) ([]*entity.URLStoreResult, error) {
+	results := make([]*entity.URLStoreResult, 0, len(urls))
+	now := time.Now()
+
 	r.mu.Lock()
 	for _, url := range urls {
+		if url.CreatedAt.IsZero() {
+			url.CreatedAt = now
+		}
+
 		r.m[url.Short] = url
+		results = append(results, &entity.URLStoreResult{URL: url, Status: entity.URLStoreCreated})
 	}
 	r.mu.Unlock()
 
-	return nil
+	return results, nil
 }
 
 // GetURL находит урл по хэшу.
@@ -64,6 +84,24 @@ func (r *URLMemoRepo) GetURL(_ context.Context, hash string) (*entity.URL, error
 	return url, nil
 }
 
+// SetURLDisabled выключает или включает неудаленный урл пользователя.
+func (r *URLMemoRepo) SetURLDisabled(_ context.Context, userUUID string, hash string, disabled bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	url, ok := r.m[hash]
+	if !ok || url.UserUUID != userUUID || url.Deleted {
+		return &URLNotFoundError{hash}
+	}
+
+	// найденные урлы отдаются наружу, поэтому изменяется копия
+	updated := *url
+	updated.Disabled = disabled
+	r.m[hash] = &updated
+
+	return nil
+}
+
 // GetUserURLS находит все урлы пользователя.
 func (r *URLMemoRepo) GetUserURLS(_ context.Context, userUUID string) ([]*entity.URL, error) {
 	urls := make([]*entity.URL, 0)
@@ -79,6 +117,72 @@ func (r *URLMemoRepo) GetUserURLS(_ context.Context, userUUID string) ([]*entity
 	return urls, nil
 }
 
+// GetUserScheduledURLs находит неудаленные урлы пользователя, которые начнут работать после now,
+// в порядке активации.
+func (r *URLMemoRepo) GetUserScheduledURLs(
+	_ context.Context,
+	userUUID string,
+	now time.Time,
+) ([]*entity.URL, error) {
+	urls := make([]*entity.URL, 0)
+
+	r.mu.Lock()
+	for _, url := range r.m {
+		if url.UserUUID == userUUID && !url.Deleted && !url.IsActive(now) {
+			urls = append(urls, url)
+		}
+	}
+	r.mu.Unlock()
+
+	slices.SortFunc(urls, func(a, b *entity.URL) int {
+		if c := a.ActiveFrom.Compare(*b.ActiveFrom); c != 0 {
+			return c
+		}
+
+		return strings.Compare(a.Short, b.Short)
+	})
+
+	return urls, nil
+}
+
+// IterateUserURLs передает в fn все урлы пользователя, включая удаленные, в порядке создания.
+// Перебор идет по снимку, поэтому fn может обращаться к репозиторию.
+func (r *URLMemoRepo) IterateUserURLs(
+	ctx context.Context,
+	userUUID string,
+	fn func(url *entity.URL) error,
+) error {
+	urls := make([]entity.URL, 0)
+
+	r.mu.Lock()
+	for _, url := range r.m {
+		if url.UserUUID == userUUID {
+			urls = append(urls, *url)
+		}
+	}
+	r.mu.Unlock()
+
+	slices.SortFunc(urls, func(a, b entity.URL) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+
+		return strings.Compare(a.Short, b.Short)
+	})
+
+	for i := range urls {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if err := fn(&urls[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // GetList возвращает все урлы, которые хранятся в памяти.
 func (r *URLMemoRepo) GetList() []*entity.URL {
 	list := make([]*entity.URL, 0, len(r.m))
@@ -101,26 +205,55 @@ func (r *URLMemoRepo) Init(urls []*entity.URL) {
 	r.mu.Unlock()
 }
 
-// DeleteMultipleURLs удаляет несколько урлов.
-func (r *URLMemoRepo) DeleteMultipleURLs(_ context.Context, userUUID string, urlHashes []string) error {
-	urlHashesToDelete := make(map[string]struct{}, len(urlHashes))
+// DeleteMultipleURLs удаляет несколько урлов, возвращает результат удаления по каждому хэшу.
+func (r *URLMemoRepo) DeleteMultipleURLs(
+	_ context.Context,
+	userUUID string,
+	urlHashes []string,
+) ([]*entity.URLDeleteResult, error) {
+	r.mu.Lock()
+	results := r.deleteUserURLs(userUUID, urlHashes)
+	r.mu.Unlock()
 
-	for _, hash := range urlHashes {
-		urlHashesToDelete[hash] = struct{}{}
-	}
+	return results, nil
+}
+
+// DeleteURLsBatch удаляет урлы нескольких пользователей,
+// возвращает результаты в том же порядке, что и переданные элементы.
+func (r *URLMemoRepo) DeleteURLsBatch(
+	_ context.Context,
+	items []*entity.URLDeleteItem,
+) ([][]*entity.URLDeleteResult, error) {
+	results := make([][]*entity.URLDeleteResult, 0, len(items))
 
 	r.mu.Lock()
-	for _, url := range r.m {
-		if url.UserUUID != userUUID {
-			continue
-		}
+	for _, item := range items {
+		results = append(results, r.deleteUserURLs(item.UserUUID, item.Hashes))
+	}
+	r.mu.Unlock()
 
-		_, shouldBeDeleted := urlHashesToDelete[url.Short]
-		if shouldBeDeleted {
+	return results, nil
+}
+
+func (r *URLMemoRepo) deleteUserURLs(userUUID string, urlHashes []string) []*entity.URLDeleteResult {
+	results := make([]*entity.URLDeleteResult, 0, len(urlHashes))
+
+	for _, hash := range urlHashes {
+		result := &entity.URLDeleteResult{Hash: hash, Status: entity.URLDeleteDone}
+
+		url, ok := r.m[hash]
+
+		switch {
+		case !ok:
+			result.Status = entity.URLDeleteNotFound
+		case url.UserUUID != userUUID:
+			result.Status = entity.URLDeleteNotOwned
+		default:
 			url.Deleted = true
 		}
+
+		results = append(results, result)
 	}
-	r.mu.Unlock()
 
-	return nil
+	return results
 }
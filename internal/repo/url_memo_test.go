@@ -0,0 +1,58 @@
+package repo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/llravell/go-shortener/internal/entity"
+)
+
+func TestURLMemoRepoDeleteMultipleURLs(t *testing.T) {
+	memoRepo := NewURLMemoRepo()
+	memoRepo.Init([]*entity.URL{
+		{Short: "a", Original: "https://a.ru", UserUUID: "user"},
+		{Short: "b", Original: "https://b.ru", UserUUID: "another-user"},
+	})
+
+	results, err := memoRepo.DeleteMultipleURLs(context.Background(), "user", []string{"a", "b", "c"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []*entity.URLDeleteResult{
+		{Hash: "a", Status: entity.URLDeleteDone},
+		{Hash: "b", Status: entity.URLDeleteNotOwned},
+		{Hash: "c", Status: entity.URLDeleteNotFound},
+	}, results)
+
+	url, err := memoRepo.GetURL(context.Background(), "a")
+	require.NoError(t, err)
+	assert.True(t, url.Deleted)
+
+	url, err = memoRepo.GetURL(context.Background(), "b")
+	require.NoError(t, err)
+	assert.False(t, url.Deleted)
+}
+
+func TestURLMemoRepoIterateUserURLs(t *testing.T) {
+	now := time.Now()
+	memoRepo := NewURLMemoRepo()
+	memoRepo.Init([]*entity.URL{
+		{Short: "b", UserUUID: "user", CreatedAt: now.Add(time.Second), Deleted: true},
+		{Short: "a", UserUUID: "user", CreatedAt: now},
+		{Short: "c", UserUUID: "another-user", CreatedAt: now},
+	})
+
+	shorts := make([]string, 0)
+
+	err := memoRepo.IterateUserURLs(context.Background(), "user", func(url *entity.URL) error {
+		shorts = append(shorts, url.Short)
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"a", "b"}, shorts)
+}
@@ -3,24 +3,46 @@ package repo
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
-	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/llravell/go-shortener/internal/entity"
 )
 
+const (
+	// storeCopyThreshold размер пачки, начиная с которого урлы загружаются через COPY.
+	storeCopyThreshold = 1000
+	// iterateFetchSize количество строк, которое читается из курсора за раз.
+	iterateFetchSize = 500
+)
+
 // URLDatabaseRepo репозиторий для хранения урлов в базе данных.
 type URLDatabaseRepo struct {
-	conn *sql.DB
+	pool    *pgxpool.Pool
+	replica *replica
 }
 
+// URLDatabaseRepoOption дополнительная опция репозитория.
+type URLDatabaseRepoOption func(r *URLDatabaseRepo)
+
 // ErrOriginalURLConflict ошибка при создании дубля.
 var ErrOriginalURLConflict = errors.New("url already exists")
 
 // NewURLDatabaseRepo создает репозиторий.
-func NewURLDatabaseRepo(conn *sql.DB) *URLDatabaseRepo {
-	return &URLDatabaseRepo{conn: conn}
+func NewURLDatabaseRepo(pool *pgxpool.Pool, opts ...URLDatabaseRepoOption) *URLDatabaseRepo {
+	r := &URLDatabaseRepo{pool: pool}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
 }
 
 func (r *URLDatabaseRepo) getNullableUserUUID(url *entity.URL) sql.NullString {
@@ -30,76 +52,253 @@ func (r *URLDatabaseRepo) getNullableUserUUID(url *entity.URL) sql.NullString {
 	}
 }
 
-// Store сохраняет урл.
+// insertURLsQuery вставляет урлы из источника source. Адрес уникален только среди неудаленных урлов:
+// удаленный урл остается как есть (его хэш продолжает отвечать 410), а рядом создается новый.
+// Живой урл с тем же адресом не меняется и не попадает в RETURNING.
+const insertURLsQuery = `
+	INSERT INTO urls (url, short, user_uuid, settings)
+	SELECT url, short, user_uuid, settings FROM %s
+	ON CONFLICT (url) WHERE NOT is_deleted DO NOTHING
+	RETURNING uuid, url, short;
+`
+
+// Store сохраняет урл. Если урл уже сокращен, возвращает его вместе с ErrOriginalURLConflict.
 func (r *URLDatabaseRepo) Store(ctx context.Context, url *entity.URL) (*entity.URL, error) {
-	storedURL, err := r.getByOriginalURL(ctx, url.Original)
-	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+	settings, err := json.Marshal(url.URLSettings)
+	if err != nil {
 		return nil, err
 	}
 
-	if storedURL != nil {
-		return storedURL, ErrOriginalURLConflict
-	}
+	row := r.pool.QueryRow(
+		ctx,
+		fmt.Sprintf(
+			insertURLsQuery,
+			"(VALUES ($1::text, $2::text, $3::uuid, $4::jsonb)) AS u(url, short, user_uuid, settings)",
+		),
+		url.Original, url.Short, r.getNullableUserUUID(url), json.RawMessage(settings),
+	)
 
-	row := r.conn.QueryRowContext(ctx, `
-		INSERT INTO urls (url, short, user_uuid)
-		VALUES
-			($1, $2, $3)
-		RETURNING uuid, url, short;
-	`, url.Original, url.Short, r.getNullableUserUUID(url))
+	var storedURL entity.URL
 
-	var returnedURL entity.URL
+	err = row.Scan(&storedURL.UUID, &storedURL.Original, &storedURL.Short)
+	if err == nil {
+		return &storedURL, nil
+	}
 
-	err = row.Scan(&returnedURL.UUID, &returnedURL.Original, &returnedURL.Short)
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+
+	existingURLs, err := r.getByOriginalURLs(ctx, r.pool, []string{url.Original})
 	if err != nil {
 		return nil, err
 	}
 
-	return &returnedURL, nil
+	existingURL, ok := existingURLs[url.Original]
+	if !ok {
+		return nil, pgx.ErrNoRows
+	}
+
+	return existingURL, ErrOriginalURLConflict
 }
 
-// StoreMultipleURLs сохраняет несколько урлов.
-func (r *URLDatabaseRepo) StoreMultipleURLs(ctx context.Context, urls []*entity.URL) error {
-	queryTemplateBase := 3
-	queryTemplateParams := make([]string, len(urls))
+// StoreMultipleURLs сохраняет несколько урлов, возвращает результат по каждому в том же порядке.
+// Уже сокращенные урлы не прерывают сохранение, а отмечаются как дубли.
+// Небольшие пачки вставляются одним запросом, крупные загружаются через COPY.
+func (r *URLDatabaseRepo) StoreMultipleURLs(ctx context.Context, urls []*entity.URL) ([]*entity.URLStoreResult, error) {
+	results := make([]*entity.URLStoreResult, 0, len(urls))
+
+	if len(urls) == 0 {
+		return results, nil
+	}
+
+	uniqueURLs := make([]*entity.URL, 0, len(urls))
+	seen := make(map[string]struct{}, len(urls))
+
+	for _, url := range urls {
+		if _, ok := seen[url.Original]; ok {
+			continue
+		}
+
+		seen[url.Original] = struct{}{}
+		uniqueURLs = append(uniqueURLs, url)
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	//nolint:errcheck // после коммита откат ничего не делает
+	defer tx.Rollback(ctx)
+
+	var insertedURLs map[string]*entity.URL
+
+	if len(uniqueURLs) >= storeCopyThreshold {
+		insertedURLs, err = r.copyURLs(ctx, tx, uniqueURLs)
+	} else {
+		insertedURLs, err = r.insertURLs(ctx, tx, uniqueURLs)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	duplicates := make([]string, 0)
+
+	for _, url := range uniqueURLs {
+		if _, ok := insertedURLs[url.Original]; !ok {
+			duplicates = append(duplicates, url.Original)
+		}
+	}
+
+	existingURLs, err := r.getByOriginalURLs(ctx, tx, duplicates)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	// Повтор адреса внутри пачки считается дублем его первого вхождения.
+	claimed := make(map[string]struct{}, len(insertedURLs))
+
+	for _, url := range urls {
+		result := &entity.URLStoreResult{URL: existingURLs[url.Original], Status: entity.URLStoreDuplicate}
+
+		if storedURL, ok := insertedURLs[url.Original]; ok {
+			result.URL = storedURL
 
-	for i := range urls {
-		offset := i * queryTemplateBase
+			if _, isClaimed := claimed[url.Original]; !isClaimed {
+				claimed[url.Original] = struct{}{}
+				result.Status = entity.URLStoreCreated
+			}
+		}
 
-		//nolint
-		queryTemplateParams[i] = fmt.Sprintf("($%d,$%d,$%d)", offset+1, offset+2, offset+3)
+		results = append(results, result)
 	}
 
-	args := make([]any, 0, len(urls))
+	return results, nil
+}
+
+func (r *URLDatabaseRepo) insertURLs(ctx context.Context, tx pgx.Tx, urls []*entity.URL) (map[string]*entity.URL, error) {
+	originals := make([]string, 0, len(urls))
+	shorts := make([]string, 0, len(urls))
+	userUUIDs := make([]sql.NullString, 0, len(urls))
+	settings := make([]string, 0, len(urls))
+
 	for _, url := range urls {
-		args = append(args, url.Original, url.Short, r.getNullableUserUUID(url))
+		urlSettings, err := json.Marshal(url.URLSettings)
+		if err != nil {
+			return nil, err
+		}
+
+		originals = append(originals, url.Original)
+		shorts = append(shorts, url.Short)
+		userUUIDs = append(userUUIDs, r.getNullableUserUUID(url))
+		settings = append(settings, string(urlSettings))
 	}
 
-	//nolint:gosec
-	query := `
-		INSERT INTO urls (url, short, user_uuid)
-			VALUES
-	` + " " + strings.Join(queryTemplateParams, ",") + ";"
+	return collectURLsByOriginal(tx.Query(
+		ctx,
+		fmt.Sprintf(
+			insertURLsQuery,
+			"unnest($1::text[], $2::text[], $3::text[]::uuid[], $4::text[]::jsonb[]) AS u(url, short, user_uuid, settings)",
+		),
+		originals, shorts, userUUIDs, settings,
+	))
+}
 
-	_, err := r.conn.ExecContext(ctx, query, args...)
+// copyURLs загружает урлы через COPY во временную таблицу и переносит их в urls,
+// потому что COPY сам по себе не умеет обрабатывать конфликты.
+func (r *URLDatabaseRepo) copyURLs(ctx context.Context, tx pgx.Tx, urls []*entity.URL) (map[string]*entity.URL, error) {
+	_, err := tx.Exec(ctx, `
+		CREATE TEMP TABLE urls_import (url text, short text, user_uuid uuid, settings jsonb) ON COMMIT DROP;
+	`)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	_, err = tx.CopyFrom(
+		ctx,
+		pgx.Identifier{"urls_import"},
+		[]string{"url", "short", "user_uuid", "settings"},
+		pgx.CopyFromSlice(len(urls), func(i int) ([]any, error) {
+			var userUUID pgtype.UUID
+
+			if urls[i].UserUUID != "" {
+				if err := userUUID.Scan(urls[i].UserUUID); err != nil {
+					return nil, err
+				}
+			}
+
+			settings, err := json.Marshal(urls[i].URLSettings)
+			if err != nil {
+				return nil, err
+			}
+
+			return []any{urls[i].Original, urls[i].Short, userUUID, json.RawMessage(settings)}, nil
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return collectURLsByOriginal(tx.Query(ctx, fmt.Sprintf(insertURLsQuery, "urls_import")))
+}
+
+func collectURLsByOriginal(rows pgx.Rows, err error) (map[string]*entity.URL, error) {
+	urls := make(map[string]*entity.URL)
+
+	if err != nil {
+		return urls, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var url entity.URL
+
+		err = rows.Scan(&url.UUID, &url.Original, &url.Short)
+		if err != nil {
+			return urls, err
+		}
+
+		urls[url.Original] = &url
+	}
+
+	return urls, rows.Err()
 }
 
 // GetURL находит урл по хэшу.
+// Если реплика не нашла урл, поиск повторяется на основной базе: урл мог быть только что создан.
 func (r *URLDatabaseRepo) GetURL(ctx context.Context, hash string) (*entity.URL, error) {
-	row := r.conn.QueryRowContext(
+	return readWithFallback(ctx, r, func(conn *pgxpool.Pool) (*entity.URL, error) {
+		return r.getURL(ctx, conn, hash)
+	}, isURLMissOrConnectionError)
+}
+
+func isURLMissOrConnectionError(err error) bool {
+	var notFoundErr *URLNotFoundError
+
+	return errors.As(err, &notFoundErr) || isConnectionError(err)
+}
+
+func (r *URLDatabaseRepo) getURL(ctx context.Context, conn *pgxpool.Pool, hash string) (*entity.URL, error) {
+	row := conn.QueryRow(
 		ctx,
-		"SELECT uuid, url, short, is_deleted FROM urls WHERE short=$1",
+		"SELECT uuid, url, short, COALESCE(user_uuid::text, ''), is_deleted, settings FROM urls WHERE short=$1",
 		hash,
 	)
 
 	var url entity.URL
 
-	err := row.Scan(&url.UUID, &url.Original, &url.Short, &url.Deleted)
+	err := row.Scan(&url.UUID, &url.Original, &url.Short, &url.UserUUID, &url.Deleted, &url.URLSettings)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, &URLNotFoundError{hash}
+	}
+
 	if err != nil {
 		return nil, err
 	}
@@ -109,9 +308,15 @@ func (r *URLDatabaseRepo) GetURL(ctx context.Context, hash string) (*entity.URL,
 
 // GetUserURLS находит все урлы пользователя.
 func (r *URLDatabaseRepo) GetUserURLS(ctx context.Context, userUUID string) ([]*entity.URL, error) {
+	return readWithFallback(ctx, r, func(conn *pgxpool.Pool) ([]*entity.URL, error) {
+		return r.getUserURLS(ctx, conn, userUUID)
+	}, isConnectionError)
+}
+
+func (r *URLDatabaseRepo) getUserURLS(ctx context.Context, conn *pgxpool.Pool, userUUID string) ([]*entity.URL, error) {
 	urls := make([]*entity.URL, 0)
 
-	rows, err := r.conn.QueryContext(
+	rows, err := conn.Query(
 		ctx,
 		"SELECT uuid, url, short FROM urls WHERE user_uuid=$1 AND NOT is_deleted",
 		userUUID,
@@ -133,59 +338,278 @@ func (r *URLDatabaseRepo) GetUserURLS(ctx context.Context, userUUID string) ([]*
 		urls = append(urls, &url)
 	}
 
-	err = rows.Err()
+	return urls, rows.Err()
+}
+
+// GetUserScheduledURLs находит неудаленные урлы пользователя, которые начнут работать после now,
+// в порядке активации.
+func (r *URLDatabaseRepo) GetUserScheduledURLs(
+	ctx context.Context,
+	userUUID string,
+	now time.Time,
+) ([]*entity.URL, error) {
+	return readWithFallback(ctx, r, func(conn *pgxpool.Pool) ([]*entity.URL, error) {
+		return r.getUserScheduledURLs(ctx, conn, userUUID, now)
+	}, isConnectionError)
+}
+
+func (r *URLDatabaseRepo) getUserScheduledURLs(
+	ctx context.Context,
+	conn *pgxpool.Pool,
+	userUUID string,
+	now time.Time,
+) ([]*entity.URL, error) {
+	urls := make([]*entity.URL, 0)
+
+	rows, err := conn.Query(ctx, `
+		SELECT uuid, url, short, settings
+		FROM urls
+		WHERE user_uuid=$1 AND NOT is_deleted AND (settings->>'active_from')::timestamptz > $2
+		ORDER BY (settings->>'active_from')::timestamptz, short;
+	`, userUUID, now)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return urls, nil
+		return urls, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		url := entity.URL{UserUUID: userUUID}
+
+		err = rows.Scan(&url.UUID, &url.Original, &url.Short, &url.URLSettings)
+		if err != nil {
+			return urls, err
 		}
 
-		return urls, err
+		urls = append(urls, &url)
 	}
 
-	return urls, nil
+	return urls, rows.Err()
 }
 
-func (r *URLDatabaseRepo) getByOriginalURL(ctx context.Context, originalURL string) (*entity.URL, error) {
-	row := r.conn.QueryRowContext(
-		ctx,
-		"SELECT uuid, url, short FROM urls WHERE url=$1 AND NOT is_deleted",
-		originalURL,
-	)
+// IterateUserURLs передает в fn все урлы пользователя, включая удаленные, в порядке создания.
+// Строки читаются из серверного курсора порциями, поэтому весь список не держится в памяти.
+func (r *URLDatabaseRepo) IterateUserURLs(
+	ctx context.Context,
+	userUUID string,
+	fn func(url *entity.URL) error,
+) error {
+	tx, err := r.readConn().BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return err
+	}
 
-	var url entity.URL
+	//nolint:errcheck // транзакция только читает данные
+	defer tx.Rollback(ctx)
 
-	err := row.Scan(&url.UUID, &url.Original, &url.Short)
+	_, err = tx.Exec(ctx, `
+		DECLARE user_urls_cursor NO SCROLL CURSOR FOR
+		SELECT uuid, url, short, is_deleted, created_at
+		FROM urls
+		WHERE user_uuid=$1
+		ORDER BY created_at, short;
+	`, userUUID)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	return &url, nil
+	for {
+		fetched, err := r.fetchUserURLs(ctx, tx, fn)
+		if err != nil {
+			return err
+		}
+
+		if fetched < iterateFetchSize {
+			return nil
+		}
+	}
 }
 
-// DeleteMultipleURLs удаляет несколько урлов.
-func (r *URLDatabaseRepo) DeleteMultipleURLs(ctx context.Context, userUUID string, urlHashes []string) error {
-	queryTemplateBase := 2
-	queryTemplateParams := make([]string, len(urlHashes))
+func (r *URLDatabaseRepo) fetchUserURLs(ctx context.Context, tx pgx.Tx, fn func(url *entity.URL) error) (int, error) {
+	rows, err := tx.Query(ctx, fmt.Sprintf("FETCH %d FROM user_urls_cursor", iterateFetchSize))
+	if err != nil {
+		return 0, err
+	}
 
-	for i := range urlHashes {
-		queryTemplateParams[i] = fmt.Sprintf("$%d", queryTemplateBase+i)
+	defer rows.Close()
+
+	fetched := 0
+
+	for rows.Next() {
+		var (
+			url       entity.URL
+			createdAt pgtype.Timestamp
+		)
+
+		err = rows.Scan(&url.UUID, &url.Original, &url.Short, &url.Deleted, &createdAt)
+		if err != nil {
+			return fetched, err
+		}
+
+		url.CreatedAt = createdAt.Time
+		fetched++
+
+		if err = fn(&url); err != nil {
+			return fetched, err
+		}
 	}
 
-	args := make([]any, 0, len(urlHashes)+1)
-	args = append(args, userUUID)
+	return fetched, rows.Err()
+}
+
+// querier общий интерфейс пула и транзакции для запросов на чтение.
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
 
-	for _, hash := range urlHashes {
-		args = append(args, hash)
+func (r *URLDatabaseRepo) getByOriginalURLs(
+	ctx context.Context,
+	conn querier,
+	originalURLs []string,
+) (map[string]*entity.URL, error) {
+	if len(originalURLs) == 0 {
+		return make(map[string]*entity.URL), nil
 	}
 
-	//nolint:gosec
-	query := `
+	return collectURLsByOriginal(conn.Query(
+		ctx,
+		"SELECT uuid, url, short FROM urls WHERE url=ANY($1::text[]) AND NOT is_deleted",
+		originalURLs,
+	))
+}
+
+type urlOwnerKey struct {
+	short    string
+	userUUID string
+}
+
+func (r *URLDatabaseRepo) queryURLOwners(ctx context.Context, query string, args ...any) (map[urlOwnerKey]struct{}, error) {
+	owners := make(map[urlOwnerKey]struct{})
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return owners, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			key      urlOwnerKey
+			userUUID sql.NullString
+		)
+
+		err = rows.Scan(&key.short, &userUUID)
+		if err != nil {
+			return owners, err
+		}
+
+		key.userUUID = userUUID.String
+		owners[key] = struct{}{}
+	}
+
+	return owners, rows.Err()
+}
+
+// SetURLDisabled выключает или включает неудаленный урл пользователя.
+func (r *URLDatabaseRepo) SetURLDisabled(ctx context.Context, userUUID string, hash string, disabled bool) error {
+	tag, err := r.pool.Exec(ctx, `
 		UPDATE urls
-		SET is_deleted=TRUE
-		WHERE user_uuid=$1 AND short IN
-	` + " (" + strings.Join(queryTemplateParams, ",") + ");"
+		SET settings = CASE WHEN $3 THEN settings || '{"disabled": true}'::jsonb ELSE settings - 'disabled' END
+		WHERE short=$1 AND user_uuid=$2::uuid AND NOT is_deleted;
+	`, hash, userUUID, disabled)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return &URLNotFoundError{hash}
+	}
 
-	_, err := r.conn.ExecContext(ctx, query, args...)
+	return nil
+}
+
+// DeleteMultipleURLs удаляет несколько урлов, возвращает результат удаления по каждому хэшу.
+func (r *URLDatabaseRepo) DeleteMultipleURLs(
+	ctx context.Context,
+	userUUID string,
+	urlHashes []string,
+) ([]*entity.URLDeleteResult, error) {
+	results, err := r.DeleteURLsBatch(ctx, []*entity.URLDeleteItem{
+		{UserUUID: userUUID, Hashes: urlHashes},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return results[0], nil
+}
+
+// DeleteURLsBatch удаляет урлы нескольких пользователей одним запросом,
+// возвращает результаты в том же порядке, что и переданные элементы.
+func (r *URLDatabaseRepo) DeleteURLsBatch(
+	ctx context.Context,
+	items []*entity.URLDeleteItem,
+) ([][]*entity.URLDeleteResult, error) {
+	userUUIDs := make([]string, 0, len(items))
+	hashes := make([]string, 0, len(items))
+
+	for _, item := range items {
+		for _, hash := range item.Hashes {
+			userUUIDs = append(userUUIDs, item.UserUUID)
+			hashes = append(hashes, hash)
+		}
+	}
+
+	deleted := make(map[urlOwnerKey]struct{})
+	existing := make(map[urlOwnerKey]struct{})
+
+	if len(hashes) != 0 {
+		var err error
+
+		deleted, err = r.queryURLOwners(ctx, `
+			UPDATE urls
+			SET is_deleted=TRUE
+			FROM unnest($1::text[], $2::text[]) AS d(user_uuid, short)
+			WHERE urls.short=d.short AND urls.user_uuid=d.user_uuid::uuid
+			RETURNING urls.short, urls.user_uuid::text;
+		`, userUUIDs, hashes)
+		if err != nil {
+			return nil, err
+		}
+
+		existing, err = r.queryURLOwners(ctx, `
+			SELECT short, user_uuid::text FROM urls WHERE short=ANY($1::text[]);
+		`, hashes)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	existingShorts := make(map[string]struct{}, len(existing))
+	for key := range existing {
+		existingShorts[key.short] = struct{}{}
+	}
+
+	results := make([][]*entity.URLDeleteResult, 0, len(items))
+
+	for _, item := range items {
+		itemResults := make([]*entity.URLDeleteResult, 0, len(item.Hashes))
+
+		for _, hash := range item.Hashes {
+			result := &entity.URLDeleteResult{Hash: hash, Status: entity.URLDeleteNotFound}
+
+			if _, ok := deleted[urlOwnerKey{short: hash, userUUID: item.UserUUID}]; ok {
+				result.Status = entity.URLDeleteDone
+			} else if _, ok = existingShorts[hash]; ok {
+				result.Status = entity.URLDeleteNotOwned
+			}
+
+			itemResults = append(itemResults, result)
+		}
+
+		results = append(results, itemResults)
+	}
 
-	return err
+	return results, nil
 }
@@ -0,0 +1,217 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/llravell/go-shortener/internal/entity"
+)
+
+//nolint:funlen
+func TestURLDatabaseRepoStoreDeletedURL(t *testing.T) {
+	ctx := context.Background()
+	pool := openTestDatabase(t)
+	r := NewURLDatabaseRepo(pool)
+
+	firstOwner := uuid.New().String()
+	secondOwner := uuid.New().String()
+
+	_, err := r.Store(ctx, &entity.URL{Original: "https://a.ru", Short: "old", UserUUID: firstOwner})
+	require.NoError(t, err)
+
+	_, err = r.DeleteMultipleURLs(ctx, firstOwner, []string{"old"})
+	require.NoError(t, err)
+
+	t.Run("Creates new url next to deleted one", func(t *testing.T) {
+		storedURL, err := r.Store(ctx, &entity.URL{Original: "https://a.ru", Short: "new", UserUUID: secondOwner})
+		require.NoError(t, err)
+		assert.Equal(t, "new", storedURL.Short)
+
+		oldURL, err := r.GetURL(ctx, "old")
+		require.NoError(t, err)
+		assert.True(t, oldURL.Deleted)
+		assert.Equal(t, firstOwner, oldURL.UserUUID)
+
+		newURL, err := r.GetURL(ctx, "new")
+		require.NoError(t, err)
+		assert.False(t, newURL.Deleted)
+		assert.Equal(t, secondOwner, newURL.UserUUID)
+	})
+
+	t.Run("Keeps deleted url in owner export", func(t *testing.T) {
+		exported := make([]*entity.URL, 0)
+
+		err := r.IterateUserURLs(ctx, firstOwner, func(url *entity.URL) error {
+			exported = append(exported, url)
+
+			return nil
+		})
+		require.NoError(t, err)
+		require.Len(t, exported, 1)
+		assert.Equal(t, "old", exported[0].Short)
+		assert.True(t, exported[0].Deleted)
+	})
+
+	t.Run("Reports conflict with alive url", func(t *testing.T) {
+		existingURL, err := r.Store(ctx, &entity.URL{Original: "https://a.ru", Short: "other"})
+		require.ErrorIs(t, err, ErrOriginalURLConflict)
+		assert.Equal(t, "new", existingURL.Short)
+	})
+
+	t.Run("Creates new url in batch next to deleted one", func(t *testing.T) {
+		_, err := r.DeleteMultipleURLs(ctx, secondOwner, []string{"new"})
+		require.NoError(t, err)
+
+		results, err := r.StoreMultipleURLs(ctx, []*entity.URL{
+			{Original: "https://a.ru", Short: "batch", UserUUID: firstOwner},
+		})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, entity.URLStoreCreated, results[0].Status)
+		assert.Equal(t, "batch", results[0].URL.Short)
+
+		for _, hash := range []string{"old", "new"} {
+			url, err := r.GetURL(ctx, hash)
+			require.NoError(t, err)
+			assert.True(t, url.Deleted)
+		}
+	})
+}
+
+func TestURLDatabaseRepoStoreConcurrently(t *testing.T) {
+	ctx := context.Background()
+	pool := openTestDatabase(t)
+	r := NewURLDatabaseRepo(pool)
+
+	const savers = 16
+
+	var wg sync.WaitGroup
+
+	urls := make([]*entity.URL, savers)
+	errs := make([]error, savers)
+
+	for i := range savers {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			urls[i], errs[i] = r.Store(ctx, &entity.URL{Original: "https://a.ru", Short: fmt.Sprintf("hash%d", i)})
+		}()
+	}
+
+	wg.Wait()
+
+	created := 0
+
+	for i := range savers {
+		if errs[i] == nil {
+			created++
+		} else {
+			require.ErrorIs(t, errs[i], ErrOriginalURLConflict)
+		}
+
+		require.NotNil(t, urls[i])
+		assert.Equal(t, urls[0].Short, urls[i].Short)
+	}
+
+	assert.Equal(t, 1, created)
+}
+
+//nolint:funlen
+func TestURLDatabaseRepoStoreMultipleURLsCopy(t *testing.T) {
+	ctx := context.Background()
+	pool := openTestDatabase(t)
+	r := NewURLDatabaseRepo(pool)
+
+	owner := uuid.New().String()
+
+	_, err := r.Store(ctx, &entity.URL{Original: "https://existing.ru", Short: "existing", UserUUID: owner})
+	require.NoError(t, err)
+
+	_, err = r.Store(ctx, &entity.URL{Original: "https://deleted.ru", Short: "deleted", UserUUID: owner})
+	require.NoError(t, err)
+
+	_, err = r.DeleteMultipleURLs(ctx, owner, []string{"deleted"})
+	require.NoError(t, err)
+
+	urls := []*entity.URL{
+		{Original: "https://existing.ru", Short: "conflict", UserUUID: owner},
+		{Original: "https://deleted.ru", Short: "restored", UserUUID: owner},
+	}
+
+	for i := len(urls); i < storeCopyThreshold; i++ {
+		urls = append(urls, &entity.URL{
+			Original: fmt.Sprintf("https://a.ru/%d", i),
+			Short:    fmt.Sprintf("copy%d", i),
+			UserUUID: owner,
+		})
+	}
+
+	results, err := r.StoreMultipleURLs(ctx, urls)
+	require.NoError(t, err)
+	require.Len(t, results, len(urls))
+
+	assert.Equal(t, entity.URLStoreDuplicate, results[0].Status)
+	assert.Equal(t, "existing", results[0].URL.Short)
+
+	assert.Equal(t, entity.URLStoreCreated, results[1].Status)
+	assert.Equal(t, "restored", results[1].URL.Short)
+
+	for i := 2; i < len(urls); i++ {
+		assert.Equal(t, entity.URLStoreCreated, results[i].Status)
+		assert.Equal(t, urls[i].Short, results[i].URL.Short)
+	}
+
+	deletedURL, err := r.GetURL(ctx, "deleted")
+	require.NoError(t, err)
+	assert.True(t, deletedURL.Deleted)
+
+	copiedURL, err := r.GetURL(ctx, "copy999")
+	require.NoError(t, err)
+	assert.Equal(t, "https://a.ru/999", copiedURL.Original)
+}
+
+func TestURLDatabaseRepoSetURLDisabled(t *testing.T) {
+	ctx := context.Background()
+	pool := openTestDatabase(t)
+	r := NewURLDatabaseRepo(pool)
+
+	owner := uuid.New().String()
+
+	_, err := r.Store(ctx, &entity.URL{
+		Original:    "https://a.ru",
+		Short:       "a",
+		UserUUID:    owner,
+		URLSettings: entity.URLSettings{Title: "title"},
+	})
+	require.NoError(t, err)
+
+	t.Run("Toggles url keeping other settings", func(t *testing.T) {
+		require.NoError(t, r.SetURLDisabled(ctx, owner, "a", true))
+
+		url, err := r.GetURL(ctx, "a")
+		require.NoError(t, err)
+		assert.True(t, url.Disabled)
+		assert.Equal(t, "title", url.Title)
+
+		require.NoError(t, r.SetURLDisabled(ctx, owner, "a", false))
+
+		url, err = r.GetURL(ctx, "a")
+		require.NoError(t, err)
+		assert.False(t, url.Disabled)
+	})
+
+	t.Run("Does not toggle url of another user", func(t *testing.T) {
+		var notFoundErr *URLNotFoundError
+
+		err := r.SetURLDisabled(ctx, uuid.New().String(), "a", true)
+		require.ErrorAs(t, err, &notFoundErr)
+	})
+}
@@ -0,0 +1,81 @@
+package repo
+
+import (
+	"context"
+	"maps"
+	"sync"
+
+	"github.com/llravell/go-shortener/internal/entity"
+)
+
+// URLClickMemoRepo репозиторий для подсчета переходов по ссылкам в оперативной памяти.
+// Хранятся только счетчики по вариантам, сами переходы не сохраняются.
+// Счетчики ведутся не больше чем для limit ссылок, при переполнении вытесняется самая старая.
+type URLClickMemoRepo struct {
+	m     map[string]*entity.URLClickStats
+	ring  []string
+	next  int
+	limit int
+	mu    sync.Mutex
+}
+
+// NewURLClickMemoRepo создает репозиторий, хранящий счетчики не больше чем для limit ссылок.
+func NewURLClickMemoRepo(limit int) *URLClickMemoRepo {
+	limit = max(limit, 1)
+
+	return &URLClickMemoRepo{
+		m:     make(map[string]*entity.URLClickStats),
+		ring:  make([]string, 0, limit),
+		limit: limit,
+	}
+}
+
+// StoreClick учитывает переход по ссылке.
+func (r *URLClickMemoRepo) StoreClick(_ context.Context, click *entity.URLClick) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stats, ok := r.m[click.Short]
+	if !ok {
+		stats = &entity.URLClickStats{Variants: make(map[string]int64)}
+		r.m[click.Short] = stats
+		r.track(click.Short)
+	}
+
+	stats.Total++
+
+	if click.Variant != "" {
+		stats.Variants[click.Variant]++
+	}
+
+	return nil
+}
+
+// track запоминает порядок появления ссылок и вытесняет самую старую при переполнении.
+func (r *URLClickMemoRepo) track(short string) {
+	if len(r.ring) < r.limit {
+		r.ring = append(r.ring, short)
+
+		return
+	}
+
+	delete(r.m, r.ring[r.next])
+	r.ring[r.next] = short
+	r.next = (r.next + 1) % r.limit
+}
+
+// GetClickStats возвращает сводку переходов по ссылке.
+func (r *URLClickMemoRepo) GetClickStats(_ context.Context, short string) (*entity.URLClickStats, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stats, ok := r.m[short]
+	if !ok {
+		return &entity.URLClickStats{Variants: make(map[string]int64)}, nil
+	}
+
+	return &entity.URLClickStats{
+		Total:    stats.Total,
+		Variants: maps.Clone(stats.Variants),
+	}, nil
+}
@@ -0,0 +1,39 @@
+package repo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/llravell/go-shortener/internal/entity"
+)
+
+func TestURLClickMemoRepoEvictsOldestLinks(t *testing.T) {
+	ctx := context.Background()
+	clickRepo := NewURLClickMemoRepo(2)
+
+	for _, click := range []*entity.URLClick{
+		{Short: "a", Variant: "x"},
+		{Short: "b"},
+		{Short: "a", Variant: "y"},
+		{Short: "c"},
+	} {
+		require.NoError(t, clickRepo.StoreClick(ctx, click))
+	}
+
+	assert.Len(t, clickRepo.m, 2)
+
+	stats, err := clickRepo.GetClickStats(ctx, "a")
+	require.NoError(t, err)
+	assert.Zero(t, stats.Total)
+
+	stats, err = clickRepo.GetClickStats(ctx, "b")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stats.Total)
+
+	stats, err = clickRepo.GetClickStats(ctx, "c")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stats.Total)
+}
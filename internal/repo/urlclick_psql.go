@@ -0,0 +1,65 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/llravell/go-shortener/internal/entity"
+)
+
+// URLClickDatabaseRepo репозиторий для хранения переходов по ссылкам в базе данных.
+type URLClickDatabaseRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewURLClickDatabaseRepo создает репозиторий.
+func NewURLClickDatabaseRepo(pool *pgxpool.Pool) *URLClickDatabaseRepo {
+	return &URLClickDatabaseRepo{pool: pool}
+}
+
+// StoreClick сохраняет переход по ссылке.
+func (r *URLClickDatabaseRepo) StoreClick(ctx context.Context, click *entity.URLClick) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO url_clicks (short, variant, created_at)
+		VALUES
+			($1, $2, $3);
+	`, click.Short, click.Variant, click.Time)
+
+	return err
+}
+
+// GetClickStats возвращает сводку переходов по ссылке.
+func (r *URLClickDatabaseRepo) GetClickStats(ctx context.Context, short string) (*entity.URLClickStats, error) {
+	stats := &entity.URLClickStats{Variants: make(map[string]int64)}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT variant, COUNT(*)
+		FROM url_clicks
+		WHERE short=$1
+		GROUP BY variant;
+	`, short)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			variant string
+			count   int64
+		)
+
+		if err = rows.Scan(&variant, &count); err != nil {
+			return nil, err
+		}
+
+		stats.Total += count
+
+		if variant != "" {
+			stats.Variants[variant] = count
+		}
+	}
+
+	return stats, rows.Err()
+}
@@ -0,0 +1,277 @@
+package repo
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/spf13/afero"
+
+	"github.com/llravell/go-shortener/internal/entity"
+)
+
+const (
+	maxJournalLineSize = 16 << 20
+	// Журнал сжимается, когда вырастает вдвое относительно последнего сжатия, но не раньше этого размера.
+	minJournalCompactSize = 1 << 20
+	journalTempSuffix     = ".tmp"
+)
+
+// URLDeleteJobFileRepo репозиторий задач удаления урлов, который дублирует каждое изменение
+// в журнал на диске. При открытии журнал воспроизводится, поэтому незавершенные задачи
+// переживают перезапуск приложения. Журнал периодически сжимается: актуальное состояние
+// записывается во временный файл, который затем атомарно заменяет журнал.
+type URLDeleteJobFileRepo struct {
+	*URLDeleteJobMemoRepo
+	fs               afero.Fs
+	file             afero.File
+	filename         string
+	size             int64
+	compactedSize    int64
+	minCompactedSize int64
+	mu               sync.Mutex
+}
+
+// NewURLDeleteJobFileRepo открывает журнал задач и восстанавливает из него состояние.
+func NewURLDeleteJobFileRepo(filename string) (*URLDeleteJobFileRepo, error) {
+	return newURLDeleteJobFileRepo(afero.NewOsFs(), filename)
+}
+
+func newURLDeleteJobFileRepo(fs afero.Fs, filename string) (*URLDeleteJobFileRepo, error) {
+	r := &URLDeleteJobFileRepo{
+		URLDeleteJobMemoRepo: NewURLDeleteJobMemoRepo(),
+		fs:                   fs,
+		filename:             filename,
+		minCompactedSize:     minJournalCompactSize,
+	}
+
+	if err := r.replay(); err != nil {
+		return nil, err
+	}
+
+	if err := r.compact(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (r *URLDeleteJobFileRepo) replay() error {
+	file, err := r.fs.OpenFile(r.filename, os.O_RDONLY|os.O_CREATE, backupFilePermissions)
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, maxJournalLineSize)
+
+	for scanner.Scan() {
+		var job entity.URLDeleteJob
+
+		if err = json.Unmarshal(scanner.Bytes(), &job); err != nil {
+			return err
+		}
+
+		r.URLDeleteJobMemoRepo.m[job.ID] = &job
+	}
+
+	return scanner.Err()
+}
+
+// compact записывает актуальное состояние задач во временный файл и подменяет им журнал.
+// Если запись прервется, прежний журнал останется нетронутым.
+func (r *URLDeleteJobFileRepo) compact() error {
+	memoRepo := r.URLDeleteJobMemoRepo
+
+	memoRepo.mu.Lock()
+
+	jobs := make([]*entity.URLDeleteJob, 0, len(memoRepo.m))
+	for _, job := range memoRepo.m {
+		jobs = append(jobs, copyURLDeleteJob(job))
+	}
+	memoRepo.mu.Unlock()
+
+	tempFilename := r.filename + journalTempSuffix
+
+	tempFile, err := r.fs.OpenFile(tempFilename, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, backupFilePermissions)
+	if err != nil {
+		return err
+	}
+
+	size, err := writeURLDeleteJobs(tempFile, jobs...)
+	if err == nil {
+		err = tempFile.Sync()
+	}
+
+	if closeErr := tempFile.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if err = r.fs.Rename(tempFilename, r.filename); err != nil {
+		return err
+	}
+
+	syncDir(r.fs, filepath.Dir(r.filename))
+
+	if r.file != nil {
+		r.file.Close()
+	}
+
+	r.file, err = r.fs.OpenFile(r.filename, os.O_WRONLY|os.O_APPEND, backupFilePermissions)
+	if err != nil {
+		return err
+	}
+
+	r.size = size
+	r.compactedSize = size
+
+	return nil
+}
+
+// syncDir фиксирует на диске переименование файла в каталоге.
+// Не все файловые системы поддерживают синхронизацию каталогов, поэтому ошибки игнорируются.
+func syncDir(fs afero.Fs, dir string) {
+	d, err := fs.Open(dir)
+	if err != nil {
+		return
+	}
+
+	_ = d.Sync()
+	_ = d.Close()
+}
+
+func writeURLDeleteJobs(file afero.File, jobs ...*entity.URLDeleteJob) (int64, error) {
+	wr := bufio.NewWriter(file)
+
+	var size int64
+
+	for _, job := range jobs {
+		data, err := json.Marshal(job)
+		if err != nil {
+			return size, err
+		}
+
+		data = append(data, '\n')
+
+		n, err := wr.Write(data)
+		size += int64(n)
+
+		if err != nil {
+			return size, err
+		}
+	}
+
+	return size, wr.Flush()
+}
+
+func (r *URLDeleteJobFileRepo) append(jobs ...*entity.URLDeleteJob) error {
+	if len(jobs) == 0 {
+		return nil
+	}
+
+	size, err := writeURLDeleteJobs(r.file, jobs...)
+	r.size += size
+
+	if err != nil {
+		return err
+	}
+
+	if err = r.file.Sync(); err != nil {
+		return err
+	}
+
+	if r.size >= max(r.minCompactedSize, 2*r.compactedSize) {
+		return r.compact()
+	}
+
+	return nil
+}
+
+// Store сохраняет задачу и записывает ее в журнал.
+func (r *URLDeleteJobFileRepo) Store(ctx context.Context, job *entity.URLDeleteJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.URLDeleteJobMemoRepo.Store(ctx, job); err != nil {
+		return err
+	}
+
+	return r.append(job)
+}
+
+// Update обновляет задачу и записывает новое состояние в журнал.
+func (r *URLDeleteJobFileRepo) Update(ctx context.Context, job *entity.URLDeleteJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.URLDeleteJobMemoRepo.Update(ctx, job); err != nil {
+		return err
+	}
+
+	updatedJob, err := r.URLDeleteJobMemoRepo.Get(ctx, job.ID)
+	if err != nil {
+		return err
+	}
+
+	return r.append(updatedJob)
+}
+
+// ClaimExpired захватывает задачи с истекшей арендой и фиксирует захват в журнале.
+func (r *URLDeleteJobFileRepo) ClaimExpired(
+	_ context.Context,
+	owner string,
+	lease time.Duration,
+	limit int,
+) ([]*entity.URLDeleteJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.URLDeleteJobMemoRepo.mu.Lock()
+	jobs := r.URLDeleteJobMemoRepo.claimExpired(owner, lease, limit)
+	r.URLDeleteJobMemoRepo.mu.Unlock()
+
+	return jobs, r.append(jobs...)
+}
+
+// ExtendLeases продлевает аренду задач владельца и фиксирует продление в журнале.
+func (r *URLDeleteJobFileRepo) ExtendLeases(_ context.Context, owner string, lease time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.URLDeleteJobMemoRepo.mu.Lock()
+	jobs := r.URLDeleteJobMemoRepo.extendLeases(owner, lease)
+	r.URLDeleteJobMemoRepo.mu.Unlock()
+
+	return r.append(jobs...)
+}
+
+// DeleteFinished удаляет завершенные задачи и сжимает журнал.
+func (r *URLDeleteJobFileRepo) DeleteFinished(ctx context.Context, before time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	deleted, err := r.URLDeleteJobMemoRepo.DeleteFinished(ctx, before)
+	if err != nil || deleted == 0 {
+		return deleted, err
+	}
+
+	return deleted, r.compact()
+}
+
+// Close закрывает файл журнала.
+func (r *URLDeleteJobFileRepo) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.file.Close()
+}
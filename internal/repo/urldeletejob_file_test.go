@@ -0,0 +1,159 @@
+package repo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/llravell/go-shortener/internal/entity"
+)
+
+func openURLDeleteJobFileRepo(t *testing.T, fs afero.Fs) *URLDeleteJobFileRepo {
+	t.Helper()
+
+	r, err := newURLDeleteJobFileRepo(fs, "test.journal")
+	require.NoError(t, err)
+
+	return r
+}
+
+func TestURLDeleteJobFileRepo(t *testing.T) {
+	ctx := context.Background()
+	staleTime := time.Now().Add(-time.Hour)
+
+	fs := afero.NewMemMapFs()
+	r := openURLDeleteJobFileRepo(t, fs)
+
+	pendingJob := &entity.URLDeleteJob{
+		ID:        "pending",
+		UserUUID:  "user",
+		Hashes:    []string{"a"},
+		Status:    entity.URLDeleteJobQueued,
+		CreatedAt: staleTime,
+		UpdatedAt: staleTime,
+	}
+	finishedJob := &entity.URLDeleteJob{
+		ID:        "finished",
+		UserUUID:  "user",
+		Hashes:    []string{"b"},
+		Status:    entity.URLDeleteJobQueued,
+		CreatedAt: staleTime,
+		UpdatedAt: staleTime,
+	}
+
+	require.NoError(t, r.Store(ctx, pendingJob))
+	require.NoError(t, r.Store(ctx, finishedJob))
+
+	finishedJob.Status = entity.URLDeleteJobSucceeded
+	finishedJob.UpdatedAt = time.Now()
+	require.NoError(t, r.Update(ctx, finishedJob))
+	require.NoError(t, r.Close())
+
+	t.Run("Restores jobs state from journal", func(t *testing.T) {
+		restored := openURLDeleteJobFileRepo(t, fs)
+		defer restored.Close()
+
+		job, err := restored.Get(ctx, "finished")
+		require.NoError(t, err)
+		assert.Equal(t, entity.URLDeleteJobSucceeded, job.Status)
+
+		job, err = restored.Get(ctx, "pending")
+		require.NoError(t, err)
+		assert.Equal(t, entity.URLDeleteJobQueued, job.Status)
+		assert.Equal(t, []string{"a"}, job.Hashes)
+	})
+
+	t.Run("Claims only unfinished jobs with expired lease once", func(t *testing.T) {
+		restored := openURLDeleteJobFileRepo(t, fs)
+		defer restored.Close()
+
+		jobs, err := restored.ClaimExpired(ctx, "owner", time.Minute, 10)
+		require.NoError(t, err)
+		require.Len(t, jobs, 1)
+		assert.Equal(t, "pending", jobs[0].ID)
+		assert.Equal(t, "owner", jobs[0].Owner)
+
+		jobs, err = restored.ClaimExpired(ctx, "peer", time.Minute, 10)
+		require.NoError(t, err)
+		assert.Empty(t, jobs)
+	})
+
+	t.Run("Keeps lease after restart", func(t *testing.T) {
+		restored := openURLDeleteJobFileRepo(t, fs)
+		defer restored.Close()
+
+		job, err := restored.Get(ctx, "pending")
+		require.NoError(t, err)
+		assert.Equal(t, "owner", job.Owner)
+		assert.True(t, job.LeaseUntil.After(time.Now()))
+	})
+}
+
+func TestURLDeleteJobFileRepoCompaction(t *testing.T) {
+	ctx := context.Background()
+	fs := afero.NewMemMapFs()
+
+	r := openURLDeleteJobFileRepo(t, fs)
+	defer r.Close()
+
+	r.minCompactedSize = 0
+
+	job := &entity.URLDeleteJob{
+		ID:        "job",
+		UserUUID:  "user",
+		Hashes:    []string{"a"},
+		Status:    entity.URLDeleteJobQueued,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	require.NoError(t, r.Store(ctx, job))
+
+	for range 10 {
+		require.NoError(t, r.ExtendLeases(ctx, "", time.Minute))
+	}
+
+	info, err := fs.Stat("test.journal")
+	require.NoError(t, err)
+	assert.LessOrEqual(t, info.Size(), 2*r.compactedSize)
+
+	exists, err := afero.Exists(fs, "test.journal"+journalTempSuffix)
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	t.Run("Removes finished jobs from journal", func(t *testing.T) {
+		job.Status = entity.URLDeleteJobSucceeded
+		require.NoError(t, r.Update(ctx, job))
+
+		deleted, err := r.DeleteFinished(ctx, time.Now().Add(time.Minute))
+		require.NoError(t, err)
+		assert.Equal(t, 1, deleted)
+
+		restored := openURLDeleteJobFileRepo(t, fs)
+		defer restored.Close()
+
+		_, err = restored.Get(ctx, "job")
+		require.ErrorIs(t, err, ErrURLDeleteJobNotFound)
+	})
+
+	t.Run("Keeps journal when temporary file is left by interrupted compaction", func(t *testing.T) {
+		require.NoError(t, r.Store(ctx, &entity.URLDeleteJob{
+			ID:        "other",
+			UserUUID:  "user",
+			Status:    entity.URLDeleteJobQueued,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}))
+		require.NoError(t, afero.WriteFile(fs, "test.journal"+journalTempSuffix, []byte("{broken"), 0o600))
+
+		restored := openURLDeleteJobFileRepo(t, fs)
+		defer restored.Close()
+
+		_, err := restored.Get(ctx, "other")
+		require.NoError(t, err)
+	})
+}
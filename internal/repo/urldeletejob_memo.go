@@ -0,0 +1,167 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/llravell/go-shortener/internal/entity"
+)
+
+// ErrURLDeleteJobNotFound ошибка поиска задачи удаления.
+var ErrURLDeleteJobNotFound = errors.New("url delete job not found")
+
+// URLDeleteJobMemoRepo репозиторий для хранения задач удаления урлов в оперативной памяти.
+// Завершенные задачи хранятся, пока их не удалит DeleteFinished.
+type URLDeleteJobMemoRepo struct {
+	m  map[string]*entity.URLDeleteJob
+	mu sync.Mutex
+}
+
+// NewURLDeleteJobMemoRepo создает репозиторий.
+func NewURLDeleteJobMemoRepo() *URLDeleteJobMemoRepo {
+	return &URLDeleteJobMemoRepo{
+		m: make(map[string]*entity.URLDeleteJob),
+	}
+}
+
+func copyURLDeleteJob(job *entity.URLDeleteJob) *entity.URLDeleteJob {
+	jobCopy := *job
+	jobCopy.Hashes = append([]string(nil), job.Hashes...)
+	jobCopy.Results = make([]*entity.URLDeleteResult, 0, len(job.Results))
+
+	for _, result := range job.Results {
+		resultCopy := *result
+		jobCopy.Results = append(jobCopy.Results, &resultCopy)
+	}
+
+	return &jobCopy
+}
+
+// Store сохраняет задачу.
+func (r *URLDeleteJobMemoRepo) Store(_ context.Context, job *entity.URLDeleteJob) error {
+	r.mu.Lock()
+	r.m[job.ID] = copyURLDeleteJob(job)
+	r.mu.Unlock()
+
+	return nil
+}
+
+// Update обновляет сохраненную задачу. Владелец и аренда задачи не меняются,
+// ими управляют ClaimExpired и ExtendLeases.
+func (r *URLDeleteJobMemoRepo) Update(_ context.Context, job *entity.URLDeleteJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.m[job.ID]
+	if !ok {
+		return ErrURLDeleteJobNotFound
+	}
+
+	updatedJob := copyURLDeleteJob(job)
+	updatedJob.Owner = stored.Owner
+	updatedJob.LeaseUntil = stored.LeaseUntil
+	r.m[job.ID] = updatedJob
+
+	return nil
+}
+
+// Get находит задачу по идентификатору.
+func (r *URLDeleteJobMemoRepo) Get(_ context.Context, id string) (*entity.URLDeleteJob, error) {
+	r.mu.Lock()
+	job, ok := r.m[id]
+	r.mu.Unlock()
+
+	if !ok {
+		return nil, ErrURLDeleteJobNotFound
+	}
+
+	return copyURLDeleteJob(job), nil
+}
+
+// ClaimExpired захватывает незавершенные задачи с истекшей арендой: назначает им владельца owner
+// и продлевает аренду на lease.
+func (r *URLDeleteJobMemoRepo) ClaimExpired(
+	_ context.Context,
+	owner string,
+	lease time.Duration,
+	limit int,
+) ([]*entity.URLDeleteJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.claimExpired(owner, lease, limit), nil
+}
+
+func (r *URLDeleteJobMemoRepo) claimExpired(owner string, lease time.Duration, limit int) []*entity.URLDeleteJob {
+	now := time.Now()
+	jobs := make([]*entity.URLDeleteJob, 0)
+
+	for id, job := range r.m {
+		if len(jobs) >= limit {
+			break
+		}
+
+		if job.IsFinished() || job.LeaseUntil.After(now) {
+			continue
+		}
+
+		claimedJob := copyURLDeleteJob(job)
+		claimedJob.Owner = owner
+		claimedJob.LeaseUntil = now.Add(lease)
+		r.m[id] = claimedJob
+
+		jobs = append(jobs, copyURLDeleteJob(claimedJob))
+	}
+
+	return jobs
+}
+
+// ExtendLeases продлевает аренду всех незавершенных задач владельца owner.
+func (r *URLDeleteJobMemoRepo) ExtendLeases(_ context.Context, owner string, lease time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.extendLeases(owner, lease)
+
+	return nil
+}
+
+func (r *URLDeleteJobMemoRepo) extendLeases(owner string, lease time.Duration) []*entity.URLDeleteJob {
+	leaseUntil := time.Now().Add(lease)
+	jobs := make([]*entity.URLDeleteJob, 0)
+
+	for _, job := range r.m {
+		if job.IsFinished() || job.Owner != owner {
+			continue
+		}
+
+		job.LeaseUntil = leaseUntil
+		jobs = append(jobs, copyURLDeleteJob(job))
+	}
+
+	return jobs
+}
+
+// DeleteFinished удаляет завершенные задачи, которые не обновлялись с момента before.
+func (r *URLDeleteJobMemoRepo) DeleteFinished(_ context.Context, before time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.deleteFinished(before), nil
+}
+
+func (r *URLDeleteJobMemoRepo) deleteFinished(before time.Time) int {
+	deleted := 0
+
+	for id, job := range r.m {
+		if job.IsFinished() && job.UpdatedAt.Before(before) {
+			delete(r.m, id)
+
+			deleted++
+		}
+	}
+
+	return deleted
+}
@@ -0,0 +1,197 @@
+package repo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/llravell/go-shortener/internal/entity"
+)
+
+// URLDeleteJobDatabaseRepo репозиторий для хранения задач удаления урлов в базе данных.
+type URLDeleteJobDatabaseRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewURLDeleteJobDatabaseRepo создает репозиторий.
+func NewURLDeleteJobDatabaseRepo(pool *pgxpool.Pool) *URLDeleteJobDatabaseRepo {
+	return &URLDeleteJobDatabaseRepo{pool: pool}
+}
+
+const urlDeleteJobColumns = `
+	id::text, user_uuid::text, hashes, status, error, results, created_at, updated_at, owner, lease_until
+`
+
+func scanURLDeleteJob(row pgx.Row) (*entity.URLDeleteJob, error) {
+	var (
+		job     entity.URLDeleteJob
+		hashes  []byte
+		results []byte
+	)
+
+	err := row.Scan(
+		&job.ID,
+		&job.UserUUID,
+		&hashes,
+		&job.Status,
+		&job.Error,
+		&results,
+		&job.CreatedAt,
+		&job.UpdatedAt,
+		&job.Owner,
+		&job.LeaseUntil,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal(hashes, &job.Hashes); err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal(results, &job.Results); err != nil {
+		return nil, err
+	}
+
+	return &job, nil
+}
+
+// Store сохраняет задачу.
+func (r *URLDeleteJobDatabaseRepo) Store(ctx context.Context, job *entity.URLDeleteJob) error {
+	hashes, err := json.Marshal(job.Hashes)
+	if err != nil {
+		return err
+	}
+
+	results, err := json.Marshal(job.Results)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.pool.Exec(ctx, `
+		INSERT INTO url_delete_jobs
+			(id, user_uuid, hashes, status, error, results, created_at, updated_at, owner, lease_until)
+		VALUES
+			($1, $2, $3, $4, $5, $6, $7, $8, $9, $10);
+	`,
+		job.ID, job.UserUUID, json.RawMessage(hashes), job.Status, job.Error, json.RawMessage(results),
+		job.CreatedAt, job.UpdatedAt, job.Owner, job.LeaseUntil,
+	)
+
+	return err
+}
+
+// Update обновляет сохраненную задачу. Владелец и аренда задачи не меняются,
+// ими управляют ClaimExpired и ExtendLeases.
+func (r *URLDeleteJobDatabaseRepo) Update(ctx context.Context, job *entity.URLDeleteJob) error {
+	results, err := json.Marshal(job.Results)
+	if err != nil {
+		return err
+	}
+
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE url_delete_jobs
+		SET status=$2, error=$3, results=$4, updated_at=$5
+		WHERE id=$1;
+	`, job.ID, job.Status, job.Error, json.RawMessage(results), job.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrURLDeleteJobNotFound
+	}
+
+	return nil
+}
+
+// Get находит задачу по идентификатору.
+func (r *URLDeleteJobDatabaseRepo) Get(ctx context.Context, id string) (*entity.URLDeleteJob, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrURLDeleteJobNotFound
+	}
+
+	row := r.pool.QueryRow(ctx, `
+		SELECT `+urlDeleteJobColumns+`
+		FROM url_delete_jobs
+		WHERE id=$1;
+	`, id)
+
+	job, err := scanURLDeleteJob(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrURLDeleteJobNotFound
+	}
+
+	return job, err
+}
+
+// ClaimExpired захватывает незавершенные задачи с истекшей арендой: назначает им владельца owner
+// и продлевает аренду на lease. Строки, захваченные другими инстансами, пропускаются.
+func (r *URLDeleteJobDatabaseRepo) ClaimExpired(
+	ctx context.Context,
+	owner string,
+	lease time.Duration,
+	limit int,
+) ([]*entity.URLDeleteJob, error) {
+	jobs := make([]*entity.URLDeleteJob, 0)
+	now := time.Now()
+
+	rows, err := r.pool.Query(ctx, `
+		UPDATE url_delete_jobs
+		SET owner=$1, lease_until=$2
+		WHERE id IN (
+			SELECT id
+			FROM url_delete_jobs
+			WHERE status IN ('queued', 'running') AND lease_until < $3
+			ORDER BY created_at
+			LIMIT $4
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+urlDeleteJobColumns+`;
+	`, owner, now.Add(lease), now, limit)
+	if err != nil {
+		return jobs, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		job, err := scanURLDeleteJob(rows)
+		if err != nil {
+			return jobs, err
+		}
+
+		jobs = append(jobs, job)
+	}
+
+	return jobs, rows.Err()
+}
+
+// ExtendLeases продлевает аренду всех незавершенных задач владельца owner.
+func (r *URLDeleteJobDatabaseRepo) ExtendLeases(ctx context.Context, owner string, lease time.Duration) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE url_delete_jobs
+		SET lease_until=$2
+		WHERE owner=$1 AND status IN ('queued', 'running');
+	`, owner, time.Now().Add(lease))
+
+	return err
+}
+
+// DeleteFinished удаляет завершенные задачи, которые не обновлялись с момента before.
+func (r *URLDeleteJobDatabaseRepo) DeleteFinished(ctx context.Context, before time.Time) (int, error) {
+	tag, err := r.pool.Exec(ctx, `
+		DELETE FROM url_delete_jobs
+		WHERE status IN ('succeeded', 'failed') AND updated_at < $1;
+	`, before)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(tag.RowsAffected()), nil
+}
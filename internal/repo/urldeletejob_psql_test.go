@@ -0,0 +1,132 @@
+package repo
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/llravell/go-shortener/internal/entity"
+)
+
+func newTestURLDeleteJob(status entity.URLDeleteJobStatus, owner string, leaseUntil time.Time) *entity.URLDeleteJob {
+	now := time.Now()
+
+	return &entity.URLDeleteJob{
+		ID:         uuid.New().String(),
+		UserUUID:   uuid.New().String(),
+		Hashes:     []string{"a"},
+		Results:    make([]*entity.URLDeleteResult, 0),
+		Status:     status,
+		Owner:      owner,
+		LeaseUntil: leaseUntil,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+}
+
+//nolint:funlen
+func TestURLDeleteJobDatabaseRepo(t *testing.T) {
+	ctx := context.Background()
+	pool := openTestDatabase(t)
+	r := NewURLDeleteJobDatabaseRepo(pool)
+
+	expiredJob := newTestURLDeleteJob(entity.URLDeleteJobRunning, "dead", time.Now().Add(-time.Minute))
+	leasedJob := newTestURLDeleteJob(entity.URLDeleteJobQueued, "alive", time.Now().Add(time.Minute))
+	finishedJob := newTestURLDeleteJob(entity.URLDeleteJobSucceeded, "dead", time.Now().Add(-time.Minute))
+
+	for _, job := range []*entity.URLDeleteJob{expiredJob, leasedJob, finishedJob} {
+		require.NoError(t, r.Store(ctx, job))
+	}
+
+	t.Run("Claims only unfinished jobs with expired lease", func(t *testing.T) {
+		jobs, err := r.ClaimExpired(ctx, "owner", time.Minute, 10)
+		require.NoError(t, err)
+		require.Len(t, jobs, 1)
+		assert.Equal(t, expiredJob.ID, jobs[0].ID)
+		assert.Equal(t, "owner", jobs[0].Owner)
+		assert.True(t, jobs[0].LeaseUntil.After(time.Now()))
+
+		jobs, err = r.ClaimExpired(ctx, "peer", time.Minute, 10)
+		require.NoError(t, err)
+		assert.Empty(t, jobs)
+	})
+
+	t.Run("Update keeps owner and lease", func(t *testing.T) {
+		staleCopy := *expiredJob
+		staleCopy.Status = entity.URLDeleteJobQueued
+		require.NoError(t, r.Update(ctx, &staleCopy))
+
+		job, err := r.Get(ctx, expiredJob.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "owner", job.Owner)
+		assert.True(t, job.LeaseUntil.After(time.Now()))
+	})
+
+	t.Run("Extends leases of owner jobs", func(t *testing.T) {
+		require.NoError(t, r.ExtendLeases(ctx, "alive", time.Hour))
+
+		job, err := r.Get(ctx, leasedJob.ID)
+		require.NoError(t, err)
+		assert.True(t, job.LeaseUntil.After(time.Now().Add(30*time.Minute)))
+	})
+
+	t.Run("Deletes old finished jobs", func(t *testing.T) {
+		deleted, err := r.DeleteFinished(ctx, time.Now().Add(time.Minute))
+		require.NoError(t, err)
+		assert.Equal(t, 1, deleted)
+
+		_, err = r.Get(ctx, finishedJob.ID)
+		require.ErrorIs(t, err, ErrURLDeleteJobNotFound)
+	})
+}
+
+func TestURLDeleteJobDatabaseRepoConcurrentClaim(t *testing.T) {
+	const (
+		jobsAmount   = 20
+		claimersSize = 4
+	)
+
+	ctx := context.Background()
+	pool := openTestDatabase(t)
+	r := NewURLDeleteJobDatabaseRepo(pool)
+
+	for range jobsAmount {
+		require.NoError(t, r.Store(ctx, newTestURLDeleteJob(entity.URLDeleteJobQueued, "", time.Now().Add(-time.Minute))))
+	}
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		claimed = make(map[string]int)
+	)
+
+	for i := range claimersSize {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			jobs, err := r.ClaimExpired(ctx, uuid.New().String(), time.Minute, jobsAmount)
+			assert.NoError(t, err, "claimer %d", i)
+
+			mu.Lock()
+			for _, job := range jobs {
+				claimed[job.ID]++
+			}
+			mu.Unlock()
+		}()
+	}
+
+	wg.Wait()
+
+	assert.Len(t, claimed, jobsAmount)
+
+	for id, times := range claimed {
+		assert.Equal(t, 1, times, "job %s claimed several times", id)
+	}
+}
@@ -0,0 +1,50 @@
+package rest
+
+import (
+	"cmp"
+	"mime"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// parseAccept возвращает типы из заголовка Accept по убыванию q-фактора.
+// Типы с нулевым q-фактором и некорректные элементы пропускаются.
+func parseAccept(accept string) []string {
+	type acceptedType struct {
+		mediaType string
+		quality   float64
+	}
+
+	accepted := make([]acceptedType, 0)
+
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			quality, err = strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+		}
+
+		if quality > 0 {
+			accepted = append(accepted, acceptedType{mediaType: mediaType, quality: quality})
+		}
+	}
+
+	slices.SortStableFunc(accepted, func(a, b acceptedType) int {
+		return cmp.Compare(b.quality, a.quality)
+	})
+
+	mediaTypes := make([]string, 0, len(accepted))
+	for _, at := range accepted {
+		mediaTypes = append(mediaTypes, at.mediaType)
+	}
+
+	return mediaTypes
+}
@@ -0,0 +1,95 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// linkError состояние ссылки, по которой нельзя перейти.
+type linkError struct {
+	key     string
+	title   string
+	message string
+	code    int
+}
+
+var (
+	linkNotFound = &linkError{
+		key:     "not_found",
+		title:   "Link not found",
+		message: "short url not found",
+		code:    http.StatusNotFound,
+	}
+	linkDeleted = &linkError{
+		key:     "deleted",
+		title:   "Link deleted",
+		message: "short url has been deleted",
+		code:    http.StatusGone,
+	}
+	linkExpired = &linkError{
+		key:     "expired",
+		title:   "Link expired",
+		message: "short url has expired",
+		code:    http.StatusGone,
+	}
+	linkNotActive = &linkError{
+		key:     "not_active",
+		title:   "Link is not active yet",
+		message: "short url is not active yet",
+		code:    http.StatusNotFound,
+	}
+	linkDisabled = &linkError{
+		key:     "disabled",
+		title:   "Link disabled",
+		message: "short url has been disabled",
+		code:    http.StatusForbidden,
+	}
+)
+
+// LinkErrorResponse dto ответа API клиенту, если по ссылке нельзя перейти.
+type LinkErrorResponse struct {
+	Error   string `json:"error"`
+	Message string `json:"message"`
+}
+
+// linkErrorPage данные шаблона страницы ошибки.
+type linkErrorPage struct {
+	Title   string
+	Message string
+	Hash    string
+	Code    int
+}
+
+// writeLinkError отвечает на переход по недоступной ссылке в формате из заголовка Accept:
+// HTML страницей для браузеров, JSON для API клиентов и текстом в остальных случаях.
+func (ur *URLRoutes) writeLinkError(w http.ResponseWriter, r *http.Request, linkErr *linkError) {
+	for _, mediaType := range parseAccept(r.Header.Get("Accept")) {
+		switch mediaType {
+		case "text/html":
+			ur.renderPage(w, linkErr.code, linkErr.key+".html", linkErrorPage{
+				Title:   linkErr.title,
+				Message: linkErr.message,
+				Hash:    r.PathValue(`id`),
+				Code:    linkErr.code,
+			})
+
+			return
+		case "application/json":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(linkErr.code)
+
+			err := json.NewEncoder(w).Encode(LinkErrorResponse{Error: linkErr.key, Message: linkErr.message})
+			if err != nil {
+				ur.log.Err(err).Msg("response write has been failed")
+			}
+
+			return
+		case "text/plain", "text/*", "*/*":
+			http.Error(w, linkErr.message, linkErr.code)
+
+			return
+		}
+	}
+
+	http.Error(w, linkErr.message, linkErr.code)
+}
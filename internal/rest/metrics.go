@@ -0,0 +1,40 @@
+package rest
+
+import (
+	"expvar"
+	"io"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog"
+)
+
+// MetricsRoutes роуты метрик приложения. В отличие от /debug/vars доступны в любом окружении
+// и отдают только метрики приложения, без командной строки и статистики рантайма.
+// Роуты не проверяют доступ сами, приложение закрывает их доверенной подсетью.
+type MetricsRoutes struct {
+	metrics expvar.Var
+	log     *zerolog.Logger
+}
+
+// NewMetricsRoutes создает роуты.
+func NewMetricsRoutes(metrics expvar.Var, log *zerolog.Logger) *MetricsRoutes {
+	return &MetricsRoutes{
+		metrics: metrics,
+		log:     log,
+	}
+}
+
+func (mr *MetricsRoutes) getMetrics(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+
+	if _, err := io.WriteString(w, mr.metrics.String()); err != nil {
+		mr.log.Err(err).Msg("response write has been failed")
+	}
+}
+
+// Apply добавляет роуты к роутеру.
+func (mr *MetricsRoutes) Apply(r chi.Router) {
+	r.Get("/metrics", mr.getMetrics)
+}
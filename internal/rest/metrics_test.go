@@ -0,0 +1,45 @@
+package rest_test
+
+import (
+	"encoding/json"
+	"expvar"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	testutils "github.com/llravell/go-shortener/internal"
+	"github.com/llravell/go-shortener/internal/rest"
+)
+
+func TestMetricsRoutes(t *testing.T) {
+	metrics := new(expvar.Map).Init()
+	metrics.Set("url_delete_workers", expvar.Func(func() any {
+		return map[string]int{"panics": 1}
+	}))
+
+	router := chi.NewRouter()
+	logger := zerolog.Nop()
+
+	rest.NewMetricsRoutes(metrics, &logger).Apply(router)
+
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	res, body := testutils.SendTestRequest(
+		t, ts, ts.Client(), http.MethodGet, "/metrics", http.NoBody, map[string]string{},
+	)
+	defer res.Body.Close()
+
+	var resp map[string]map[string]int
+
+	require.NoError(t, json.Unmarshal(body, &resp))
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "application/json", res.Header.Get("Content-Type"))
+	assert.Equal(t, 1, resp["url_delete_workers"]["panics"])
+}
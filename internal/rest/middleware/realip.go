@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ParseTrustedProxies разбирает список адресов и подсетей доверенных прокси, разделенных запятыми.
+func ParseTrustedProxies(value string) ([]*net.IPNet, error) {
+	proxies := make([]*net.IPNet, 0)
+
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		if !strings.Contains(item, "/") {
+			ip := net.ParseIP(item)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", item)
+			}
+
+			bits := net.IPv6len * 8
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), net.IPv4len*8
+			}
+
+			proxies = append(proxies, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+
+			continue
+		}
+
+		_, ipNet, err := net.ParseCIDR(item)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", item, err)
+		}
+
+		proxies = append(proxies, ipNet)
+	}
+
+	return proxies, nil
+}
+
+func isTrustedProxy(ip net.IP, proxies []*net.IPNet) bool {
+	if ip == nil {
+		return false
+	}
+
+	for _, proxy := range proxies {
+		if proxy.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func remoteIP(remoteAddr string) net.IP {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+
+	return net.ParseIP(host)
+}
+
+// forwardedClientIP ищет адрес клиента в X-Forwarded-For справа налево, пропуская доверенные прокси:
+// левее первого недоверенного адреса значения мог подставить сам клиент. Без X-Forwarded-For
+// используется X-Real-IP.
+func forwardedClientIP(r *http.Request, proxies []*net.IPNet) net.IP {
+	forwardedFor := r.Header.Values("X-Forwarded-For")
+	if len(forwardedFor) == 0 {
+		return net.ParseIP(strings.TrimSpace(r.Header.Get("X-Real-IP")))
+	}
+
+	hops := strings.Split(strings.Join(forwardedFor, ","), ",")
+
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := net.ParseIP(strings.TrimSpace(hops[i]))
+		if ip == nil {
+			return nil
+		}
+
+		if !isTrustedProxy(ip, proxies) {
+			return ip
+		}
+	}
+
+	return nil
+}
+
+// RealIP подменяет RemoteAddr запроса адресом клиента из X-Forwarded-For или X-Real-IP.
+// Заголовки учитываются, только если запрос пришел от доверенного прокси, иначе их может подделать клиент.
+func RealIP(trustedProxies []*net.IPNet) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isTrustedProxy(remoteIP(r.RemoteAddr), trustedProxies) {
+				if ip := forwardedClientIP(r, trustedProxies); ip != nil {
+					r.RemoteAddr = ip.String()
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
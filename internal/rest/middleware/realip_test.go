@@ -0,0 +1,93 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/llravell/go-shortener/internal/rest/middleware"
+)
+
+func TestParseTrustedProxies(t *testing.T) {
+	proxies, err := middleware.ParseTrustedProxies("10.0.0.0/8, 192.168.1.1,::1")
+	require.NoError(t, err)
+	require.Len(t, proxies, 3)
+	assert.Equal(t, "192.168.1.1/32", proxies[1].String())
+	assert.Equal(t, "::1/128", proxies[2].String())
+
+	proxies, err = middleware.ParseTrustedProxies("")
+	require.NoError(t, err)
+	assert.Empty(t, proxies)
+
+	_, err = middleware.ParseTrustedProxies("10.0.0.0/33")
+	require.Error(t, err)
+
+	_, err = middleware.ParseTrustedProxies("proxy")
+	require.Error(t, err)
+}
+
+func TestRealIP(t *testing.T) {
+	proxies, err := middleware.ParseTrustedProxies("10.0.0.0/8")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name       string
+		remoteAddr string
+		headers    map[string]string
+		expected   string
+	}{
+		{
+			name:       "Keeps remote address without proxy headers",
+			remoteAddr: "10.0.0.1:1234",
+			expected:   "10.0.0.1:1234",
+		},
+		{
+			name:       "Ignores headers from untrusted peer",
+			remoteAddr: "1.1.1.1:1234",
+			headers:    map[string]string{"X-Forwarded-For": "2.2.2.2", "X-Real-IP": "3.3.3.3"},
+			expected:   "1.1.1.1:1234",
+		},
+		{
+			name:       "Takes last untrusted hop of forwarded chain",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    map[string]string{"X-Forwarded-For": "6.6.6.6, 2.2.2.2, 10.0.0.2"},
+			expected:   "2.2.2.2",
+		},
+		{
+			name:       "Falls back to real ip header",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    map[string]string{"X-Real-IP": "3.3.3.3"},
+			expected:   "3.3.3.3",
+		},
+		{
+			name:       "Ignores malformed forwarded chain",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    map[string]string{"X-Forwarded-For": "2.2.2.2, garbage"},
+			expected:   "10.0.0.1:1234",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var remoteAddr string
+
+			handler := middleware.RealIP(proxies)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				remoteAddr = r.RemoteAddr
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+			req.RemoteAddr = tc.remoteAddr
+
+			for name, value := range tc.headers {
+				req.Header.Set(name, value)
+			}
+
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Equal(t, tc.expected, remoteAddr)
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+)
+
+// TrustedSubnet пропускает только запросы клиентов из доверенной подсети, остальным отвечает 403.
+// Без подсети закрыт для всех. Адрес клиента берется из RemoteAddr, поэтому за прокси
+// middleware нужно ставить после RealIP.
+func TrustedSubnet(subnet *net.IPNet) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip := remoteIP(r.RemoteAddr)
+			if subnet == nil || ip == nil || !subnet.Contains(ip) {
+				http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package middleware_test
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/llravell/go-shortener/internal/rest/middleware"
+)
+
+func TestTrustedSubnet(t *testing.T) {
+	_, subnet, err := net.ParseCIDR("10.0.0.0/8")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name         string
+		subnet       *net.IPNet
+		remoteAddr   string
+		expectedCode int
+	}{
+		{
+			name:         "Allows client from subnet",
+			subnet:       subnet,
+			remoteAddr:   "10.0.0.1:1234",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Allows client address without port",
+			subnet:       subnet,
+			remoteAddr:   "10.1.2.3",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Forbids client outside subnet",
+			subnet:       subnet,
+			remoteAddr:   "1.1.1.1:1234",
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "Forbids everyone without subnet",
+			remoteAddr:   "10.0.0.1:1234",
+			expectedCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := middleware.TrustedSubnet(tc.subnet)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/metrics", http.NoBody)
+			req.RemoteAddr = tc.remoteAddr
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedCode, rec.Code)
+		})
+	}
+}
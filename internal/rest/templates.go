@@ -0,0 +1,55 @@
+package rest
+
+import (
+	"embed"
+	"html/template"
+	"net/http"
+	"path/filepath"
+)
+
+//go:embed templates/*.html
+var templatesFS embed.FS
+
+var pageTemplates = template.Must(parseEmbeddedTemplates())
+
+func parseEmbeddedTemplates() (*template.Template, error) {
+	return template.ParseFS(templatesFS, "templates/*.html")
+}
+
+// LoadTemplates возвращает встроенные шаблоны страниц, переопределенные файлами *.html из каталога dir.
+// Файл заменяет встроенный шаблон с тем же именем, поэтому можно поменять как отдельную страницу,
+// так и общий макет страниц ошибок error_layout.html. Пустой dir оставляет встроенные шаблоны.
+func LoadTemplates(dir string) (*template.Template, error) {
+	if dir == "" {
+		return pageTemplates, nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.html"))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(files) == 0 {
+		return pageTemplates, nil
+	}
+
+	// Клонировать pageTemplates нельзя, если они уже исполнялись, поэтому шаблоны разбираются заново.
+	templates, err := parseEmbeddedTemplates()
+	if err != nil {
+		return nil, err
+	}
+
+	return templates.ParseFiles(files...)
+}
+
+// renderPage отдает HTML страницу из шаблона. Страницы зависят от ссылки
+// и посетителя, поэтому не кэшируются.
+func (ur *URLRoutes) renderPage(w http.ResponseWriter, code int, name string, data any) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+
+	if err := ur.templates.ExecuteTemplate(w, name, data); err != nil {
+		ur.log.Err(err).Msg("response write has been failed")
+	}
+}
@@ -4,8 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"hash/fnv"
+	"html/template"
 	"io"
+	"net"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/rs/zerolog"
@@ -15,25 +21,60 @@ import (
 	"github.com/llravell/go-shortener/internal/usecase"
 )
 
+const (
+	retryAfterSeconds = "1"
+	variantCookieTTL  = 30 * 24 * time.Hour
+)
+
 // URLUseCase юзкейс базовых операций с урлами.
 type URLUseCase interface {
-	SaveURL(ctx context.Context, url string, userUUID string) (*entity.URL, error)
-	SaveURLMultiple(ctx context.Context, urls []string, userUUID string) ([]*entity.URL, error)
+	SaveURL(ctx context.Context, draft *entity.URLDraft, userUUID string) (*entity.URL, error)
+	SaveURLMultiple(
+		ctx context.Context,
+		drafts []*entity.URLDraft,
+		userUUID string,
+		mode usecase.URLBatchMode,
+	) ([]*entity.URLStoreResult, error)
 	ResolveURL(ctx context.Context, hash string) (*entity.URL, error)
+	GetUserURL(ctx context.Context, userUUID string, hash string) (*entity.URL, error)
 	GetUserURLS(ctx context.Context, userUUID string) ([]*entity.URL, error)
+	SetURLDisabled(ctx context.Context, userUUID string, hash string, disabled bool) error
+	GetScheduledURLs(ctx context.Context, userUUID string) ([]*entity.URL, error)
+	ExportUserURLs(ctx context.Context, userUUID string, fn func(url *entity.URL) error) error
 	BuildRedirectURL(url *entity.URL) string
-	QueueDelete(item *entity.URLDeleteItem) error
+	BuildDestinationURL(url *entity.URL, visit *entity.Visit) *entity.Destination
+	RecordClick(ctx context.Context, url *entity.URL, destination *entity.Destination) error
+	GetClickStats(ctx context.Context, userUUID string, hash string) (*entity.URLClickStats, error)
+	RedirectCode(url *entity.URL) int
+	RedirectMaxAge(url *entity.URL) time.Duration
+	NotActiveCode() int
+	VerifyPassword(url *entity.URL, clientKey string, password string) error
+	QueueDelete(ctx context.Context, item *entity.URLDeleteItem) (*entity.URLDeleteJob, error)
+	GetDeleteJob(ctx context.Context, userUUID string, jobID string) (*entity.URLDeleteJob, error)
 }
 
 // URLRoutes роуты базовых операций с урлами.
 type URLRoutes struct {
-	urlUC URLUseCase
-	auth  *middleware.Auth
-	log   *zerolog.Logger
+	urlUC     URLUseCase
+	auth      *middleware.Auth
+	log       *zerolog.Logger
+	templates *template.Template
+}
+
+// URLRoutesOption дополнительная опция роутов.
+type URLRoutesOption func(ur *URLRoutes)
+
+// Templates задает шаблоны HTML страниц, например, загруженные через LoadTemplates.
+func Templates(templates *template.Template) URLRoutesOption {
+	return func(ur *URLRoutes) {
+		ur.templates = templates
+	}
 }
 
 type saveURLRequest struct {
-	URL string `json:"url"`
+	URL      string `json:"url"`
+	Password string `json:"password,omitempty"`
+	entity.URLSettings
 }
 
 type saveURLResponse struct {
@@ -44,12 +85,24 @@ type saveURLResponse struct {
 type URLBatchRequestItem struct {
 	CorrelationID string `json:"correlation_id"`
 	OriginalURL   string `json:"original_url"`
+	Password      string `json:"password,omitempty"`
+	entity.URLSettings
+}
+
+func (item *URLBatchRequestItem) draft() *entity.URLDraft {
+	return &entity.URLDraft{
+		Original:    item.OriginalURL,
+		Password:    item.Password,
+		URLSettings: item.URLSettings,
+	}
 }
 
 // URLBatchResponseItem dto ответа для массового создания урлов.
 type URLBatchResponseItem struct {
-	CorrelationID string `json:"correlation_id"`
-	ShortURL      string `json:"short_url"`
+	CorrelationID string                `json:"correlation_id"`
+	ShortURL      string                `json:"short_url,omitempty"`
+	Status        entity.URLStoreStatus `json:"status"`
+	Reason        string                `json:"reason,omitempty"`
 }
 
 // UserURLItem dto урла пользователя.
@@ -58,17 +111,52 @@ type UserURLItem struct {
 	OriginalURL string `json:"original_url"`
 }
 
+// ScheduledURLItem dto ссылки пользователя, которая еще не начала работать.
+type ScheduledURLItem struct {
+	ActiveFrom  time.Time `json:"active_from"`
+	ShortURL    string    `json:"short_url"`
+	OriginalURL string    `json:"original_url"`
+}
+
+// URLStateRequest dto запроса на изменение доступности ссылки.
+type URLStateRequest struct {
+	Disabled *bool `json:"disabled"`
+}
+
+// URLDeleteResponse dto ответа на постановку урлов в очередь на удаление.
+type URLDeleteResponse struct {
+	JobID string `json:"job_id"`
+}
+
+// URLDeleteJobItem dto задачи удаления урлов.
+type URLDeleteJobItem struct {
+	CreatedAt time.Time                 `json:"created_at"`
+	UpdatedAt time.Time                 `json:"updated_at"`
+	ID        string                    `json:"id"`
+	Status    entity.URLDeleteJobStatus `json:"status"`
+	Error     string                    `json:"error,omitempty"`
+	Results   []*entity.URLDeleteResult `json:"results"`
+}
+
 // NewURLRoutes создает роуты.
 func NewURLRoutes(
 	urlUC URLUseCase,
 	auth *middleware.Auth,
 	log *zerolog.Logger,
+	opts ...URLRoutesOption,
 ) *URLRoutes {
-	return &URLRoutes{
-		urlUC: urlUC,
-		auth:  auth,
-		log:   log,
+	ur := &URLRoutes{
+		urlUC:     urlUC,
+		auth:      auth,
+		log:       log,
+		templates: pageTemplates,
+	}
+
+	for _, opt := range opts {
+		opt(ur)
 	}
+
+	return ur
 }
 
 func (ur *URLRoutes) getUserUUIDFromRequest(r *http.Request) string {
@@ -107,8 +195,14 @@ func (ur *URLRoutes) saveURLLegacy(w http.ResponseWriter, r *http.Request) {
 
 	statusCode := http.StatusCreated
 
-	urlObj, err := ur.urlUC.SaveURL(r.Context(), url, userUUID)
+	urlObj, err := ur.urlUC.SaveURL(r.Context(), &entity.URLDraft{Original: url}, userUUID)
 	if err != nil {
+		if errors.Is(err, usecase.ErrURLInvalid) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+
 		if errors.Is(err, usecase.ErrURLDuplicate) {
 			statusCode = http.StatusConflict
 		} else {
@@ -144,8 +238,18 @@ func (ur *URLRoutes) saveURL(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	urlObj, err := ur.urlUC.SaveURL(r.Context(), urlReq.URL, userUUID)
+	urlObj, err := ur.urlUC.SaveURL(r.Context(), &entity.URLDraft{
+		Original:    urlReq.URL,
+		Password:    urlReq.Password,
+		URLSettings: urlReq.URLSettings,
+	}, userUUID)
 	if err != nil {
+		if errors.Is(err, usecase.ErrURLInvalid) || errors.Is(err, usecase.ErrURLSettingsInvalid) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+
 		if errors.Is(err, usecase.ErrURLDuplicate) {
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusConflict)
@@ -171,6 +275,30 @@ func (ur *URLRoutes) saveURL(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// batchStatusCode выбирает код ответа по результатам пачки: 201, если все урлы созданы,
+// 422, если ничего не сохранено из-за невалидных урлов, и 207 в остальных случаях.
+func batchStatusCode(results []*entity.URLStoreResult) int {
+	var created, invalid int
+
+	for _, result := range results {
+		switch result.Status {
+		case entity.URLStoreCreated:
+			created++
+		case entity.URLStoreInvalid:
+			invalid++
+		}
+	}
+
+	switch {
+	case created == len(results):
+		return http.StatusCreated
+	case invalid != 0 && created == 0:
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusMultiStatus
+	}
+}
+
 func (ur *URLRoutes) saveURLMultiple(w http.ResponseWriter, r *http.Request) {
 	var batchItems []URLBatchRequestItem
 
@@ -180,6 +308,17 @@ func (ur *URLRoutes) saveURLMultiple(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	mode := usecase.URLBatchMode(r.URL.Query().Get("mode"))
+	if mode == "" {
+		mode = usecase.URLBatchBestEffort
+	}
+
+	if mode != usecase.URLBatchAtomic && mode != usecase.URLBatchBestEffort {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+
+		return
+	}
+
 	userUUID := ur.getUserUUIDFromRequest(r)
 	if userUUID == "" {
 		w.WriteHeader(http.StatusUnauthorized)
@@ -187,12 +326,12 @@ func (ur *URLRoutes) saveURLMultiple(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	urls := make([]string, 0, len(batchItems))
-	for _, item := range batchItems {
-		urls = append(urls, item.OriginalURL)
+	drafts := make([]*entity.URLDraft, 0, len(batchItems))
+	for i := range batchItems {
+		drafts = append(drafts, batchItems[i].draft())
 	}
 
-	urlObjs, err := ur.urlUC.SaveURLMultiple(r.Context(), urls, userUUID)
+	results, err := ur.urlUC.SaveURLMultiple(r.Context(), drafts, userUUID, mode)
 	if err != nil {
 		http.Error(w, "saving url failed", http.StatusInternalServerError)
 
@@ -201,17 +340,22 @@ func (ur *URLRoutes) saveURLMultiple(w http.ResponseWriter, r *http.Request) {
 
 	responseItems := make([]URLBatchResponseItem, 0, len(batchItems))
 
-	for i, urlObj := range urlObjs {
+	for i, result := range results {
 		item := URLBatchResponseItem{
 			CorrelationID: batchItems[i].CorrelationID,
-			ShortURL:      ur.urlUC.BuildRedirectURL(urlObj),
+			Status:        result.Status,
+			Reason:        result.Reason,
+		}
+
+		if result.URL != nil {
+			item.ShortURL = ur.urlUC.BuildRedirectURL(result.URL)
 		}
 
 		responseItems = append(responseItems, item)
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
+	w.WriteHeader(batchStatusCode(results))
 
 	err = json.NewEncoder(w).Encode(responseItems)
 	if err != nil {
@@ -221,25 +365,178 @@ func (ur *URLRoutes) saveURLMultiple(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func (ur *URLRoutes) resolveURL(w http.ResponseWriter, r *http.Request) {
-	hash := r.PathValue(`id`)
+// clientIP возвращает адрес клиента. За прокси RemoteAddr заранее подменяется
+// адресом из заголовков мидлварой middleware.RealIP.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+func newVisit(r *http.Request) *entity.Visit {
+	return &entity.Visit{
+		Time:           time.Now(),
+		IP:             net.ParseIP(clientIP(r)),
+		Query:          r.URL.Query(),
+		UserAgent:      r.UserAgent(),
+		AcceptLanguage: r.Header.Get("Accept-Language"),
+	}
+}
 
-	url, err := ur.urlUC.ResolveURL(r.Context(), hash)
+// resolveActiveURL находит ссылку из пути запроса. Для неизвестной ссылки отвечает 404,
+// для удаленной или истекшей 410, чтобы поисковики и CDN могли отличить их друг от друга.
+func (ur *URLRoutes) resolveActiveURL(w http.ResponseWriter, r *http.Request) (*entity.URL, bool) {
+	url, err := ur.urlUC.ResolveURL(r.Context(), r.PathValue(`id`))
 	if err != nil {
-		http.Error(w, "Bad request", http.StatusBadRequest)
+		if errors.Is(err, usecase.ErrURLNotFound) {
+			ur.writeLinkError(w, r, linkNotFound)
+		} else {
+			http.Error(w, "resolving url failed", http.StatusInternalServerError)
+		}
+
+		return nil, false
+	}
+
+	switch {
+	case url.Deleted:
+		ur.writeLinkError(w, r, linkDeleted)
+	case url.Disabled:
+		ur.writeLinkError(w, r, linkDisabled)
+	case !url.IsActive(time.Now()):
+		ur.writeNotActive(w, r, url)
+	case url.IsExpired(time.Now()):
+		ur.writeLinkError(w, r, linkExpired)
+	default:
+		return url, true
+	}
+
+	return nil, false
+}
 
+// writeNotActive отвечает на переход по ссылке до времени активации кодом из настроек юзкейса.
+// С кодом 404 отдается обычная страница несуществующей ссылки, чтобы не раскрывать ее заранее.
+// Время активации сообщается только при коде 503, который и означает временную недоступность.
+func (ur *URLRoutes) writeNotActive(w http.ResponseWriter, r *http.Request, url *entity.URL) {
+	notActive := *linkNotActive
+	notActive.code = ur.urlUC.NotActiveCode()
+
+	// с кодом 404 ссылка должна быть неотличима от несуществующей
+	if notActive.code == http.StatusNotFound {
+		ur.writeLinkError(w, r, linkNotFound)
+
+		return
+	}
+
+	if notActive.code == http.StatusServiceUnavailable {
+		w.Header().Set("Retry-After", url.ActiveFrom.UTC().Format(http.TimeFormat))
+	}
+
+	ur.writeLinkError(w, r, &notActive)
+}
+
+func (ur *URLRoutes) resolveURL(w http.ResponseWriter, r *http.Request) {
+	url, ok := ur.resolveActiveURL(w, r)
+	if !ok {
 		return
 	}
 
-	if url.Deleted {
-		w.WriteHeader(http.StatusGone)
+	if url.HasPassword() {
+		ur.renderPasswordForm(w, r, url.Short, http.StatusOK, "")
 
 		return
 	}
 
-	ur.log.Info().Str("url", url.Original).Msg("redirect")
+	if url.Interstitial {
+		ur.renderPreview(w, r, url)
 
-	http.Redirect(w, r, url.Original, http.StatusTemporaryRedirect)
+		return
+	}
+
+	ur.redirect(w, r, url, ur.urlUC.RedirectCode(url))
+}
+
+// redirect перенаправляет на урл назначения ссылки, закрепляя вариант за посетителем и учитывая переход.
+func (ur *URLRoutes) redirect(w http.ResponseWriter, r *http.Request, url *entity.URL, code int) {
+	visit := newVisit(r)
+	cookieName := variantCookieName(url.Short)
+
+	if cookie, cookieErr := r.Cookie(cookieName); url.StickyVariant && cookieErr == nil {
+		visit.Variant = cookie.Value
+	}
+
+	destination := ur.urlUC.BuildDestinationURL(url, visit)
+
+	if url.StickyVariant && destination.Variant != "" && destination.Variant != visit.Variant {
+		http.SetCookie(w, &http.Cookie{
+			Name:     cookieName,
+			Value:    destination.Variant,
+			Path:     "/" + url.Short,
+			MaxAge:   int(variantCookieTTL.Seconds()),
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		})
+	}
+
+	// HEAD запросы присылают проверщики ссылок, поэтому они не считаются переходами.
+	if r.Method != http.MethodHead {
+		if err := ur.urlUC.RecordClick(r.Context(), url, destination); err != nil {
+			ur.log.Err(err).Str("hash", url.Short).Msg("click recording has been failed")
+		}
+	}
+
+	ur.log.Info().
+		Str("url", destination.URL).
+		Str("variant", destination.Variant).
+		Msg("redirect")
+
+	maxAge := ur.urlUC.RedirectMaxAge(url)
+	if r.Method == http.MethodPost || maxAge <= 0 {
+		w.Header().Set("Cache-Control", "no-store")
+		http.Redirect(w, r, destination.URL, code)
+
+		return
+	}
+
+	etag := redirectETag(code, destination.URL)
+
+	w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(int(maxAge.Seconds())))
+	w.Header().Set("ETag", etag)
+
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+
+		return
+	}
+
+	http.Redirect(w, r, destination.URL, code)
+}
+
+// redirectETag сильный валидатор редиректа, зависящий от кода и урла назначения.
+func redirectETag(code int, destination string) string {
+	hash := fnv.New64a()
+	hash.Write([]byte(strconv.Itoa(code) + " " + destination))
+
+	return `"` + strconv.FormatUint(hash.Sum64(), 16) + `"`
+}
+
+// etagMatches проверяет заголовок If-None-Match, который может содержать список тегов или *.
+func etagMatches(ifNoneMatch string, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// variantCookieName имя куки, в которой закреплен вариант ссылки за посетителем.
+func variantCookieName(hash string) string {
+	return "variant_" + hash
 }
 
 func (ur *URLRoutes) getUserURLS(w http.ResponseWriter, r *http.Request) {
@@ -279,6 +576,63 @@ func (ur *URLRoutes) getUserURLS(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (ur *URLRoutes) getScheduledURLs(w http.ResponseWriter, r *http.Request) {
+	scheduledURLs, err := ur.urlUC.GetScheduledURLs(r.Context(), ur.getUserUUIDFromRequest(r))
+	if err != nil {
+		http.Error(w, "searching urls failed", http.StatusInternalServerError)
+
+		return
+	}
+
+	if len(scheduledURLs) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+
+		return
+	}
+
+	responseItems := make([]ScheduledURLItem, 0, len(scheduledURLs))
+
+	for _, urlObj := range scheduledURLs {
+		responseItems = append(responseItems, ScheduledURLItem{
+			ActiveFrom:  *urlObj.ActiveFrom,
+			ShortURL:    ur.urlUC.BuildRedirectURL(urlObj),
+			OriginalURL: urlObj.Original,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	err = json.NewEncoder(w).Encode(responseItems)
+	if err != nil {
+		ur.log.Err(err).Msg("response write has been failed")
+
+		return
+	}
+}
+
+func (ur *URLRoutes) updateURLState(w http.ResponseWriter, r *http.Request) {
+	var req URLStateRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Disabled == nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+
+		return
+	}
+
+	err := ur.urlUC.SetURLDisabled(r.Context(), ur.getUserUUIDFromRequest(r), r.PathValue(`id`), *req.Disabled)
+	if err != nil {
+		if errors.Is(err, usecase.ErrURLNotFound) {
+			http.Error(w, "url not found", http.StatusNotFound)
+		} else {
+			http.Error(w, "updating url failed", http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 func (ur *URLRoutes) deleteUserURLS(w http.ResponseWriter, r *http.Request) {
 	var urlHashes []string
 
@@ -288,22 +642,92 @@ func (ur *URLRoutes) deleteUserURLS(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := ur.urlUC.QueueDelete(&entity.URLDeleteItem{
+	job, err := ur.urlUC.QueueDelete(r.Context(), &entity.URLDeleteItem{
 		UserUUID: ur.getUserUUIDFromRequest(r),
 		Hashes:   urlHashes,
 	})
 	if err != nil {
-		http.Error(w, "delete urls failed", http.StatusInternalServerError)
+		if errors.Is(err, usecase.ErrURLDeleteQueueFull) {
+			w.Header().Set("Retry-After", retryAfterSeconds)
+			http.Error(w, "delete queue is full", http.StatusServiceUnavailable)
+		} else {
+			http.Error(w, "delete urls failed", http.StatusInternalServerError)
+		}
 
 		return
 	}
 
+	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusAccepted)
+
+	err = json.NewEncoder(w).Encode(URLDeleteResponse{JobID: job.ID})
+	if err != nil {
+		ur.log.Err(err).Msg("response write has been failed")
+
+		return
+	}
+}
+
+func (ur *URLRoutes) getDeleteJob(w http.ResponseWriter, r *http.Request) {
+	job, err := ur.urlUC.GetDeleteJob(r.Context(), ur.getUserUUIDFromRequest(r), r.PathValue(`id`))
+	if err != nil {
+		if errors.Is(err, usecase.ErrURLDeleteJobNotFound) {
+			http.Error(w, "delete job not found", http.StatusNotFound)
+		} else {
+			http.Error(w, "searching delete job failed", http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	resp := URLDeleteJobItem{
+		ID:        job.ID,
+		Status:    job.Status,
+		Error:     job.Error,
+		Results:   job.Results,
+		CreatedAt: job.CreatedAt,
+		UpdatedAt: job.UpdatedAt,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	err = json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		ur.log.Err(err).Msg("response write has been failed")
+
+		return
+	}
+}
+
+func (ur *URLRoutes) getClickStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := ur.urlUC.GetClickStats(r.Context(), ur.getUserUUIDFromRequest(r), r.PathValue(`id`))
+	if err != nil {
+		if errors.Is(err, usecase.ErrURLNotFound) {
+			http.Error(w, "url not found", http.StatusNotFound)
+		} else {
+			http.Error(w, "searching url stats failed", http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	err = json.NewEncoder(w).Encode(stats)
+	if err != nil {
+		ur.log.Err(err).Msg("response write has been failed")
+
+		return
+	}
 }
 
 // Apply добавляет роуты к роутеру.
 func (ur *URLRoutes) Apply(r chi.Router) {
 	r.Get("/{id}", ur.resolveURL)
+	r.Head("/{id}", ur.resolveURL)
+	r.Get("/{id}+", ur.previewURL)
+	r.Get("/{id}/qr", ur.getQRCode)
+	r.Post("/{id}", ur.unlockURL)
 	r.With(middleware.DecompressMiddleware()).
 		With(ur.auth.ProvideJWTMiddleware).
 		Post("/", ur.saveURLLegacy)
@@ -317,6 +741,7 @@ func (ur *URLRoutes) Apply(r chi.Router) {
 
 			r.Post("/", ur.saveURL)
 			r.Post("/batch", ur.saveURLMultiple)
+			r.Post("/import", ur.importURLs)
 		})
 
 		r.Route("/user", func(r chi.Router) {
@@ -324,7 +749,13 @@ func (ur *URLRoutes) Apply(r chi.Router) {
 				r.Use(ur.auth.CheckJWTMiddleware)
 
 				r.Get("/", ur.getUserURLS)
+				r.Get("/export", ur.exportUserURLS)
+				r.Get("/scheduled", ur.getScheduledURLs)
 				r.Delete("/", ur.deleteUserURLS)
+				r.Get("/delete-jobs/{id}", ur.getDeleteJob)
+				r.Patch("/{id}", ur.updateURLState)
+				r.Get("/{id}/stats", ur.getClickStats)
+				r.Get("/{id}/qr", ur.getUserQRCode)
 			})
 		})
 	})
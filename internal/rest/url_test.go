@@ -1,14 +1,21 @@
 package rest_test
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"image/png"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/golang/mock/gomock"
@@ -23,6 +30,7 @@ import (
 	"github.com/llravell/go-shortener/internal/rest"
 	"github.com/llravell/go-shortener/internal/rest/middleware"
 	"github.com/llravell/go-shortener/internal/usecase"
+	"github.com/llravell/go-shortener/pkg/workerpool"
 )
 
 var errNotFound = errors.New("not found")
@@ -57,18 +65,60 @@ func toJSON(t *testing.T, m any) string {
 	return string(data)
 }
 
+type testServerOptions struct {
+	useCaseOpts []usecase.URLUseCaseOption
+	routesOpts  []rest.URLRoutesOption
+	middlewares []func(http.Handler) http.Handler
+}
+
+type testServerOption func(o *testServerOptions)
+
+func withUseCaseOptions(opts ...usecase.URLUseCaseOption) testServerOption {
+	return func(o *testServerOptions) {
+		o.useCaseOpts = append(o.useCaseOpts, opts...)
+	}
+}
+
+func withRoutesOptions(opts ...rest.URLRoutesOption) testServerOption {
+	return func(o *testServerOptions) {
+		o.routesOpts = append(o.routesOpts, opts...)
+	}
+}
+
+func withMiddlewares(middlewares ...func(http.Handler) http.Handler) testServerOption {
+	return func(o *testServerOptions) {
+		o.middlewares = append(o.middlewares, middlewares...)
+	}
+}
+
 func prepareTestServer(
 	gen usecase.HashGenerator,
 	repo usecase.URLRepo,
 	wp usecase.URLDeleteWorkerPool,
+	opts ...testServerOption,
 ) *httptest.Server {
 	logger := zerolog.Nop()
 
-	urlUseCase := usecase.NewURLUseCase(repo, wp, gen, "http://localhost:8080", logger)
+	var o testServerOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	urlUseCase := usecase.NewURLUseCase(
+		repo,
+		repository.NewURLDeleteJobMemoRepo(),
+		wp,
+		gen,
+		"http://localhost:8080",
+		logger,
+		o.useCaseOpts...,
+	)
 
 	router := chi.NewRouter()
-	auth := middleware.NewAuth("secret", &logger)
-	urlRoutes := rest.NewURLRoutes(urlUseCase, auth, &logger)
+	router.Use(o.middlewares...)
+
+	auth := middleware.NewAuth(testutils.JWTSecretKey, &logger)
+	urlRoutes := rest.NewURLRoutes(urlUseCase, auth, &logger, o.routesOpts...)
 
 	urlRoutes.Apply(router)
 
@@ -125,6 +175,14 @@ func TestURLBaseRoutes(t *testing.T) {
 			prepareMocks: func() {},
 			expectedCode: http.StatusBadRequest,
 		},
+		{
+			name:         "[legacy] sending malformed url",
+			method:       http.MethodPost,
+			path:         "/",
+			body:         strings.NewReader("not a url"),
+			prepareMocks: func() {},
+			expectedCode: http.StatusBadRequest,
+		},
 		{
 			name:   "Sending url",
 			method: http.MethodPost,
@@ -158,6 +216,53 @@ func TestURLBaseRoutes(t *testing.T) {
 			prepareMocks: func() {},
 			expectedCode: http.StatusBadRequest,
 		},
+		{
+			name:         "Sending url with unsupported scheme",
+			method:       http.MethodPost,
+			path:         "/api/shorten",
+			body:         strings.NewReader(toJSON(t, map[string]string{"url": "ftp://a.ru"})),
+			prepareMocks: func() {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:   "Sending url with unknown query policy",
+			method: http.MethodPost,
+			path:   "/api/shorten",
+			body: strings.NewReader(toJSON(t, map[string]any{
+				"url":          "https://a.ru",
+				"query_policy": "merge",
+			})),
+			prepareMocks: func() {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "Sending url with unsupported redirect code",
+			method:       http.MethodPost,
+			path:         "/api/shorten",
+			body:         strings.NewReader(toJSON(t, map[string]any{"url": "https://a.ru", "redirect_code": 200})),
+			prepareMocks: func() {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "Sending url with too long title",
+			method:       http.MethodPost,
+			path:         "/api/shorten",
+			body:         strings.NewReader(toJSON(t, map[string]any{"url": "https://a.ru", "title": strings.Repeat("a", 201)})),
+			prepareMocks: func() {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:   "Sending url activated after expiration",
+			method: http.MethodPost,
+			path:   "/api/shorten",
+			body: strings.NewReader(toJSON(t, map[string]any{
+				"url":         "https://a.ru",
+				"active_from": time.Now().Add(2 * time.Hour),
+				"expires_at":  time.Now().Add(time.Hour),
+			})),
+			prepareMocks: func() {},
+			expectedCode: http.StatusBadRequest,
+		},
 		{
 			name:   "Redirect on url",
 			method: http.MethodGet,
@@ -169,6 +274,20 @@ func TestURLBaseRoutes(t *testing.T) {
 			},
 			expectedCode: http.StatusTemporaryRedirect,
 		},
+		{
+			name:   "Redirect on url with own redirect code",
+			method: http.MethodGet,
+			path:   "/permanent",
+			prepareMocks: func() {
+				repo.EXPECT().
+					GetURL(gomock.Any(), "permanent").
+					Return(&entity.URL{
+						Original:    "https://a.ru",
+						URLSettings: entity.URLSettings{RedirectCode: http.StatusPermanentRedirect},
+					}, nil)
+			},
+			expectedCode: http.StatusPermanentRedirect,
+		},
 		{
 			name:   "Failed redirect",
 			method: http.MethodGet,
@@ -176,9 +295,21 @@ func TestURLBaseRoutes(t *testing.T) {
 			prepareMocks: func() {
 				repo.EXPECT().
 					GetURL(gomock.Any(), "not_existed_hash").
+					Return(nil, repository.NewURLNotFoundError("not_existed_hash"))
+			},
+			expectedCode: http.StatusNotFound,
+			expectedBody: "short url not found\n",
+		},
+		{
+			name:   "Failed redirect on storage error",
+			method: http.MethodGet,
+			path:   "/broken",
+			prepareMocks: func() {
+				repo.EXPECT().
+					GetURL(gomock.Any(), "broken").
 					Return(nil, errNotFound)
 			},
-			expectedCode: http.StatusBadRequest,
+			expectedCode: http.StatusInternalServerError,
 		},
 		{
 			name:   "Redirect on deleted url",
@@ -190,6 +321,18 @@ func TestURLBaseRoutes(t *testing.T) {
 					Return(&entity.URL{Deleted: true}, nil)
 			},
 			expectedCode: http.StatusGone,
+			expectedBody: "short url has been deleted\n",
+		},
+		{
+			name:   "Redirect on url by head request",
+			method: http.MethodHead,
+			path:   "/a",
+			prepareMocks: func() {
+				repo.EXPECT().
+					GetURL(gomock.Any(), "a").
+					Return(&entity.URL{Original: "https://a.ru"}, nil)
+			},
+			expectedCode: http.StatusTemporaryRedirect,
 		},
 	}
 
@@ -241,20 +384,117 @@ func TestURLBatchRoute(t *testing.T) {
 
 				repo.EXPECT().
 					StoreMultipleURLs(gomock.Any(), gomock.Any()).
-					Return(nil)
+					DoAndReturn(func(_ context.Context, urls []*entity.URL) ([]*entity.URLStoreResult, error) {
+						results := make([]*entity.URLStoreResult, 0, len(urls))
+						for _, url := range urls {
+							results = append(results, &entity.URLStoreResult{URL: url, Status: entity.URLStoreCreated})
+						}
+
+						return results, nil
+					})
 			},
 			expectedCode: http.StatusCreated,
-			expectedBody: toJSON(t, []map[string]string{
+			expectedBody: toJSON(t, []rest.URLBatchResponseItem{
+				{
+					CorrelationID: "1",
+					ShortURL:      "http://localhost:8080/a",
+					Status:        entity.URLStoreCreated,
+				},
+				{
+					CorrelationID: "2",
+					ShortURL:      "http://localhost:8080/b",
+					Status:        entity.URLStoreCreated,
+				},
+			}),
+		},
+		{
+			name:   "Sending several urls with duplicate and invalid url",
+			method: http.MethodPost,
+			path:   "/api/shorten/batch",
+			body: strings.NewReader(toJSON(t, []map[string]string{
+				{
+					"correlation_id": "1",
+					"original_url":   "https://a.ru",
+				},
+				{
+					"correlation_id": "2",
+					"original_url":   "ftp://b.ru",
+				},
+				{
+					"correlation_id": "3",
+					"original_url":   "https://c.ru",
+				},
+			})),
+			prepareMocks: func() {
+				gomock.InOrder(
+					gen.EXPECT().Generate().Return("a", nil),
+					gen.EXPECT().Generate().Return("c", nil),
+				)
+
+				repo.EXPECT().
+					StoreMultipleURLs(gomock.Any(), gomock.Len(2)).
+					Return([]*entity.URLStoreResult{
+						{URL: &entity.URL{Short: "a"}, Status: entity.URLStoreCreated},
+						{URL: &entity.URL{Short: "d"}, Status: entity.URLStoreDuplicate},
+					}, nil)
+			},
+			expectedCode: http.StatusMultiStatus,
+			expectedBody: toJSON(t, []rest.URLBatchResponseItem{
+				{
+					CorrelationID: "1",
+					ShortURL:      "http://localhost:8080/a",
+					Status:        entity.URLStoreCreated,
+				},
+				{
+					CorrelationID: "2",
+					Status:        entity.URLStoreInvalid,
+					Reason:        "url scheme must be http or https",
+				},
+				{
+					CorrelationID: "3",
+					ShortURL:      "http://localhost:8080/d",
+					Status:        entity.URLStoreDuplicate,
+				},
+			}),
+		},
+		{
+			name:   "Sending invalid url in atomic mode",
+			method: http.MethodPost,
+			path:   "/api/shorten/batch?mode=atomic",
+			body: strings.NewReader(toJSON(t, []map[string]string{
 				{
 					"correlation_id": "1",
-					"short_url":      "http://localhost:8080/a",
+					"original_url":   "https://a.ru",
 				},
 				{
 					"correlation_id": "2",
-					"short_url":      "http://localhost:8080/b",
+					"original_url":   "",
+				},
+			})),
+			prepareMocks: func() {
+				gen.EXPECT().Generate().Return("a", nil)
+			},
+			expectedCode: http.StatusUnprocessableEntity,
+			expectedBody: toJSON(t, []rest.URLBatchResponseItem{
+				{
+					CorrelationID: "1",
+					Status:        entity.URLStoreSkipped,
+				},
+				{
+					CorrelationID: "2",
+					Status:        entity.URLStoreInvalid,
+					Reason:        "url is empty",
 				},
 			}),
 		},
+		{
+			name:         "Sending unknown mode",
+			method:       http.MethodPost,
+			path:         "/api/shorten/batch?mode=partial",
+			body:         strings.NewReader(toJSON(t, []any{})),
+			prepareMocks: func() {},
+			expectedCode: http.StatusBadRequest,
+		},
 		{
 			name:         "Sending empty urls",
 			method:       http.MethodPost,
@@ -282,6 +522,76 @@ func TestURLBatchRoute(t *testing.T) {
 	}
 }
 
+func TestURLImportRoute(t *testing.T) {
+	gen := mocks.NewMockHashGenerator(gomock.NewController(t))
+	repo := mocks.NewMockURLRepo(gomock.NewController(t))
+	wp := mocks.NewMockURLDeleteWorkerPool(gomock.NewController(t))
+
+	ts := prepareTestServer(gen, repo, wp)
+	defer ts.Close()
+
+	gomock.InOrder(
+		gen.EXPECT().Generate().Return("a", nil),
+		gen.EXPECT().Generate().Return("b", nil),
+	)
+
+	gomock.InOrder(
+		repo.EXPECT().
+			StoreMultipleURLs(gomock.Any(), gomock.Len(1)).
+			Return([]*entity.URLStoreResult{
+				{URL: &entity.URL{Short: "a"}, Status: entity.URLStoreCreated},
+			}, nil),
+		repo.EXPECT().
+			StoreMultipleURLs(gomock.Any(), gomock.Len(1)).
+			Return([]*entity.URLStoreResult{
+				{URL: &entity.URL{Short: "c"}, Status: entity.URLStoreDuplicate},
+			}, nil),
+	)
+
+	body := toJSON(t, rest.URLBatchRequestItem{CorrelationID: "1", OriginalURL: "https://a.ru"}) +
+		"{broken\n" +
+		"\n" +
+		toJSON(t, rest.URLBatchRequestItem{CorrelationID: "2", OriginalURL: "https://b.ru"})
+
+	res, respBody := testutils.SendTestRequest(
+		t,
+		ts,
+		ts.Client(),
+		http.MethodPost,
+		"/api/shorten/import",
+		strings.NewReader(body),
+		map[string]string{},
+	)
+	defer res.Body.Close()
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "application/x-ndjson", res.Header.Get("Content-Type"))
+
+	expectedBody := toJSON(t, rest.URLImportResponseItem{
+		Line: 1,
+		URLBatchResponseItem: rest.URLBatchResponseItem{
+			CorrelationID: "1",
+			ShortURL:      "http://localhost:8080/a",
+			Status:        entity.URLStoreCreated,
+		},
+	}) + toJSON(t, rest.URLImportResponseItem{
+		Line: 2,
+		URLBatchResponseItem: rest.URLBatchResponseItem{
+			Status: entity.URLStoreInvalid,
+			Reason: "line is not a valid json object",
+		},
+	}) + toJSON(t, rest.URLImportResponseItem{
+		Line: 4,
+		URLBatchResponseItem: rest.URLBatchResponseItem{
+			CorrelationID: "2",
+			ShortURL:      "http://localhost:8080/c",
+			Status:        entity.URLStoreDuplicate,
+		},
+	})
+
+	assert.Equal(t, expectedBody, string(respBody))
+}
+
 //nolint:funlen
 func TestURLUserRoutes(t *testing.T) {
 	gen := mocks.NewMockHashGenerator(gomock.NewController(t))
@@ -356,15 +666,1065 @@ func TestURLUserRoutes(t *testing.T) {
 			hashes:   hashes,
 		}
 
-		wp.EXPECT().QueueWork(workMatcher).Return(nil)
+		wp.EXPECT().TryQueueWork(workMatcher).Return(nil)
 
 		body := strings.NewReader(toJSON(t, hashes))
-		res, _ := testutils.SendTestRequest(
+		res, resBody := testutils.SendTestRequest(
 			t, ts, testutils.AuthorizedClient(t, ts), http.MethodDelete, "/api/user/urls", body, map[string]string{},
 		)
 
 		defer res.Body.Close()
 
+		var deleteResp rest.URLDeleteResponse
+
+		require.NoError(t, json.Unmarshal(resBody, &deleteResp))
+
 		assert.Equal(t, http.StatusAccepted, res.StatusCode)
+		assert.NotEmpty(t, deleteResp.JobID)
+	})
+
+	t.Run("Return service unavailable status code for full delete queue", func(t *testing.T) {
+		wp.EXPECT().TryQueueWork(gomock.Any()).Return(workerpool.ErrQueueFull)
+
+		body := strings.NewReader(toJSON(t, []string{"a"}))
+		res, _ := testutils.SendTestRequest(
+			t, ts, testutils.AuthorizedClient(t, ts), http.MethodDelete, "/api/user/urls", body, map[string]string{},
+		)
+
+		defer res.Body.Close()
+
+		assert.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+		assert.NotEmpty(t, res.Header.Get("Retry-After"))
+	})
+
+	t.Run("Return queued delete job", func(t *testing.T) {
+		wp.EXPECT().TryQueueWork(gomock.Any()).Return(nil)
+
+		client := testutils.AuthorizedClient(t, ts)
+		body := strings.NewReader(toJSON(t, []string{"a"}))
+		res, resBody := testutils.SendTestRequest(
+			t, ts, client, http.MethodDelete, "/api/user/urls", body, map[string]string{},
+		)
+
+		defer res.Body.Close()
+
+		var deleteResp rest.URLDeleteResponse
+
+		require.NoError(t, json.Unmarshal(resBody, &deleteResp))
+
+		jobRes, jobBody := testutils.SendTestRequest(
+			t, ts, client, http.MethodGet, "/api/user/urls/delete-jobs/"+deleteResp.JobID, http.NoBody, map[string]string{},
+		)
+		defer jobRes.Body.Close()
+
+		var job rest.URLDeleteJobItem
+
+		require.NoError(t, json.Unmarshal(jobBody, &job))
+
+		assert.Equal(t, http.StatusOK, jobRes.StatusCode)
+		assert.Equal(t, deleteResp.JobID, job.ID)
+		assert.Equal(t, entity.URLDeleteJobQueued, job.Status)
+	})
+
+	t.Run("Return not found status code for unknown delete job", func(t *testing.T) {
+		res, _ := testutils.SendTestRequest(
+			t, ts, testutils.AuthorizedClient(t, ts), http.MethodGet, "/api/user/urls/delete-jobs/unknown", http.NoBody,
+			map[string]string{},
+		)
+		defer res.Body.Close()
+
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+}
+
+//nolint:funlen
+func TestURLExportRoute(t *testing.T) {
+	gen := mocks.NewMockHashGenerator(gomock.NewController(t))
+	repo := mocks.NewMockURLRepo(gomock.NewController(t))
+	wp := mocks.NewMockURLDeleteWorkerPool(gomock.NewController(t))
+
+	ts := prepareTestServer(gen, repo, wp)
+	defer ts.Close()
+
+	createdAt := time.Date(2024, time.December, 1, 12, 0, 0, 0, time.UTC)
+	urls := []*entity.URL{
+		{Short: "a", Original: "https://a.ru", CreatedAt: createdAt},
+		{Short: "b", Original: "https://b.ru", CreatedAt: createdAt, Deleted: true},
+	}
+
+	repo.EXPECT().
+		IterateUserURLs(gomock.Any(), testutils.UserUUID, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, fn func(url *entity.URL) error) error {
+			for _, url := range urls {
+				if err := fn(url); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}).
+		AnyTimes()
+
+	items := []rest.URLExportItem{
+		{ShortURL: "http://localhost:8080/a", OriginalURL: "https://a.ru", CreatedAt: createdAt},
+		{ShortURL: "http://localhost:8080/b", OriginalURL: "https://b.ru", CreatedAt: createdAt, IsDeleted: true},
+	}
+
+	testCases := []struct {
+		name                string
+		accept              string
+		expectedCode        int
+		expectedContentType string
+		expectedBody        string
+	}{
+		{
+			name:                "Export as json by default",
+			accept:              "",
+			expectedCode:        http.StatusOK,
+			expectedContentType: "application/json",
+			expectedBody: strings.Join([]string{
+				"[" + toJSON(t, items[0]),
+				"," + toJSON(t, items[1]),
+				"]\n",
+			}, ""),
+		},
+		{
+			name:                "Export as ndjson",
+			accept:              "application/x-ndjson",
+			expectedCode:        http.StatusOK,
+			expectedContentType: "application/x-ndjson",
+			expectedBody:        toJSON(t, items[0]) + toJSON(t, items[1]),
+		},
+		{
+			name:                "Export as csv by quality",
+			accept:              "application/json;q=0.5, text/csv",
+			expectedCode:        http.StatusOK,
+			expectedContentType: "text/csv",
+			expectedBody: "short_url,original_url,created_at,is_deleted\n" +
+				"http://localhost:8080/a,https://a.ru,2024-12-01T12:00:00Z,false\n" +
+				"http://localhost:8080/b,https://b.ru,2024-12-01T12:00:00Z,true\n",
+		},
+		{
+			name:         "Reject unsupported format",
+			accept:       "application/xml",
+			expectedCode: http.StatusNotAcceptable,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, body := testutils.SendTestRequest(
+				t,
+				ts,
+				testutils.AuthorizedClient(t, ts),
+				http.MethodGet,
+				"/api/user/urls/export",
+				http.NoBody,
+				map[string]string{"Accept": tc.accept},
+			)
+			defer res.Body.Close()
+
+			assert.Equal(t, tc.expectedCode, res.StatusCode)
+
+			if tc.expectedContentType != "" {
+				assert.Equal(t, tc.expectedContentType, res.Header.Get("Content-Type"))
+				assert.Equal(t, tc.expectedBody, string(body))
+			}
+		})
+	}
+}
+
+func TestURLExportRouteWithoutURLs(t *testing.T) {
+	gen := mocks.NewMockHashGenerator(gomock.NewController(t))
+	repo := mocks.NewMockURLRepo(gomock.NewController(t))
+	wp := mocks.NewMockURLDeleteWorkerPool(gomock.NewController(t))
+
+	ts := prepareTestServer(gen, repo, wp)
+	defer ts.Close()
+
+	testCases := []struct {
+		name                string
+		accept              string
+		iterateErr          error
+		expectedCode        int
+		expectedContentType string
+		expectedBody        string
+	}{
+		{
+			name:                "Export empty json",
+			accept:              "application/json",
+			expectedCode:        http.StatusOK,
+			expectedContentType: "application/json",
+			expectedBody:        "[]\n",
+		},
+		{
+			name:                "Export empty csv with header only",
+			accept:              "text/csv",
+			expectedCode:        http.StatusOK,
+			expectedContentType: "text/csv",
+			expectedBody:        "short_url,original_url,created_at,is_deleted\n",
+		},
+		{
+			name:                "Fail csv export before first url",
+			accept:              "text/csv",
+			iterateErr:          errors.New("iterate failed"),
+			expectedCode:        http.StatusInternalServerError,
+			expectedContentType: "text/plain; charset=utf-8",
+			expectedBody:        "exporting urls failed\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo.EXPECT().
+				IterateUserURLs(gomock.Any(), testutils.UserUUID, gomock.Any()).
+				Return(tc.iterateErr)
+
+			res, body := testutils.SendTestRequest(
+				t,
+				ts,
+				testutils.AuthorizedClient(t, ts),
+				http.MethodGet,
+				"/api/user/urls/export",
+				http.NoBody,
+				map[string]string{"Accept": tc.accept},
+			)
+			defer res.Body.Close()
+
+			assert.Equal(t, tc.expectedCode, res.StatusCode)
+			assert.Equal(t, tc.expectedContentType, res.Header.Get("Content-Type"))
+			assert.Equal(t, tc.expectedBody, string(body))
+
+			if tc.iterateErr != nil {
+				assert.Empty(t, res.Header.Get("Content-Disposition"))
+			}
+		})
+	}
+}
+
+func TestURLVariantRoutes(t *testing.T) {
+	urlRepo := repository.NewURLMemoRepo()
+
+	ts := prepareTestServer(nil, urlRepo, nil,
+		withUseCaseOptions(usecase.ClickAnalytics(repository.NewURLClickMemoRepo(100))),
+	)
+	defer ts.Close()
+
+	variants := map[string]string{"a": "https://a.ru/a", "b": "https://a.ru/b"}
+
+	_, err := urlRepo.Store(context.Background(), &entity.URL{
+		Short:    "ab",
+		Original: "https://a.ru",
+		UserUUID: testutils.UserUUID,
+		URLSettings: entity.URLSettings{
+			Variants: []entity.URLVariant{
+				{Name: "a", Destination: variants["a"], Weight: 1},
+				{Name: "b", Destination: variants["b"], Weight: 1},
+			},
+			StickyVariant: true,
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = urlRepo.Store(context.Background(), &entity.URL{Short: "foreign", UserUUID: "another-uuid"})
+	require.NoError(t, err)
+
+	client := testutils.AuthorizedClient(t, ts)
+
+	res, _ := testutils.SendTestRequest(t, ts, client, http.MethodGet, "/ab", http.NoBody, map[string]string{})
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusTemporaryRedirect, res.StatusCode)
+
+	var served string
+
+	for _, cookie := range res.Cookies() {
+		if cookie.Name == "variant_ab" {
+			served = cookie.Value
+		}
+	}
+
+	require.Contains(t, variants, served)
+	assert.Equal(t, variants[served], res.Header.Get("Location"))
+
+	t.Run("Keeps sticky variant", func(t *testing.T) {
+		res, _ := testutils.SendTestRequest(t, ts, client, http.MethodGet, "/ab", http.NoBody, map[string]string{})
+		defer res.Body.Close()
+
+		assert.Equal(t, variants[served], res.Header.Get("Location"))
+		assert.Empty(t, res.Cookies())
+	})
+
+	t.Run("Returns clicks by served variant", func(t *testing.T) {
+		res, body := testutils.SendTestRequest(
+			t, ts, client, http.MethodGet, "/api/user/urls/ab/stats", http.NoBody, map[string]string{},
+		)
+		defer res.Body.Close()
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, toJSON(t, &entity.URLClickStats{
+			Total:    2,
+			Variants: map[string]int64{served: 2},
+		}), string(body))
+	})
+
+	t.Run("Hides stats of another user's url", func(t *testing.T) {
+		res, _ := testutils.SendTestRequest(
+			t, ts, client, http.MethodGet, "/api/user/urls/foreign/stats", http.NoBody, map[string]string{},
+		)
+		defer res.Body.Close()
+
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+}
+
+func TestURLRulesBehindProxy(t *testing.T) {
+	urlRepo := repository.NewURLMemoRepo()
+	geo := mocks.NewMockGeoLocator(gomock.NewController(t))
+
+	_, err := urlRepo.Store(context.Background(), &entity.URL{
+		Short:    "geo",
+		Original: "https://a.ru",
+		URLSettings: entity.URLSettings{
+			Rules: []entity.URLRule{{Countries: []string{"DE"}, Destination: "https://a.ru/de"}},
+		},
+	})
+	require.NoError(t, err)
+
+	// Тестовый клиент подключается с локального адреса, поэтому считается прокси.
+	trustedProxies, err := middleware.ParseTrustedProxies("127.0.0.1")
+	require.NoError(t, err)
+
+	ts := prepareTestServer(nil, urlRepo, nil,
+		withUseCaseOptions(usecase.GeoLocation(geo)),
+		withMiddlewares(middleware.RealIP(trustedProxies)),
+	)
+	defer ts.Close()
+
+	t.Run("Locates client by forwarded address", func(t *testing.T) {
+		geo.EXPECT().Country(net.ParseIP("2.2.2.2")).Return("DE", nil)
+
+		res, _ := testutils.SendTestRequest(
+			t, ts, ts.Client(), http.MethodGet, "/geo", http.NoBody,
+			map[string]string{"X-Forwarded-For": "2.2.2.2"},
+		)
+		defer res.Body.Close()
+
+		assert.Equal(t, http.StatusTemporaryRedirect, res.StatusCode)
+		assert.Equal(t, "https://a.ru/de", res.Header.Get("Location"))
+	})
+
+	t.Run("Falls back to original url for another country", func(t *testing.T) {
+		geo.EXPECT().Country(net.ParseIP("3.3.3.3")).Return("FR", nil)
+
+		res, _ := testutils.SendTestRequest(
+			t, ts, ts.Client(), http.MethodGet, "/geo", http.NoBody,
+			map[string]string{"X-Forwarded-For": "3.3.3.3"},
+		)
+		defer res.Body.Close()
+
+		assert.Equal(t, http.StatusTemporaryRedirect, res.StatusCode)
+		assert.Equal(t, "https://a.ru", res.Header.Get("Location"))
+	})
+}
+
+//nolint:funlen
+func TestURLPasswordRoutes(t *testing.T) {
+	gen := mocks.NewMockHashGenerator(gomock.NewController(t))
+
+	gen.EXPECT().Generate().Return("locked", nil)
+
+	// Тестовый клиент подключается с локального адреса, поэтому считается прокси.
+	trustedProxies, err := middleware.ParseTrustedProxies("127.0.0.1")
+	require.NoError(t, err)
+
+	ts := prepareTestServer(gen, repository.NewURLMemoRepo(), nil,
+		withUseCaseOptions(usecase.PasswordAttempts(3, time.Minute)),
+		withMiddlewares(middleware.RealIP(trustedProxies)),
+	)
+	defer ts.Close()
+
+	client := testutils.AuthorizedClient(t, ts)
+	formHeaders := map[string]string{"Content-Type": "application/x-www-form-urlencoded"}
+
+	res, _ := testutils.SendTestRequest(
+		t, ts, client, http.MethodPost, "/api/shorten",
+		strings.NewReader(toJSON(t, map[string]string{
+			"url":           "https://a.ru/doc",
+			"password":      "passcode",
+			"password_hash": "forged",
+		})),
+		map[string]string{},
+	)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+
+	t.Run("Serves password form instead of redirect", func(t *testing.T) {
+		res, body := testutils.SendTestRequest(t, ts, client, http.MethodGet, "/locked", http.NoBody, map[string]string{})
+		defer res.Body.Close()
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "no-store", res.Header.Get("Cache-Control"))
+		assert.Contains(t, string(body), `<input type="password" name="password"`)
+	})
+
+	t.Run("Rejects wrong password", func(t *testing.T) {
+		res, body := testutils.SendTestRequest(
+			t, ts, client, http.MethodPost, "/locked", strings.NewReader("password=forged"), formHeaders,
+		)
+		defer res.Body.Close()
+
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+		assert.Contains(t, string(body), "Wrong password.")
+	})
+
+	t.Run("Redirects with correct password", func(t *testing.T) {
+		res, _ := testutils.SendTestRequest(
+			t, ts, client, http.MethodPost, "/locked", strings.NewReader("password=passcode"), formHeaders,
+		)
+		defer res.Body.Close()
+
+		assert.Equal(t, http.StatusSeeOther, res.StatusCode)
+		assert.Equal(t, "https://a.ru/doc", res.Header.Get("Location"))
+	})
+
+	t.Run("Limits password attempts", func(t *testing.T) {
+		for range 3 {
+			res, _ := testutils.SendTestRequest(
+				t, ts, client, http.MethodPost, "/locked", strings.NewReader("password=wrong"), formHeaders,
+			)
+			res.Body.Close()
+
+			require.Equal(t, http.StatusUnauthorized, res.StatusCode)
+		}
+
+		res, _ := testutils.SendTestRequest(
+			t, ts, client, http.MethodPost, "/locked", strings.NewReader("password=passcode"), formHeaders,
+		)
+		defer res.Body.Close()
+
+		assert.Equal(t, http.StatusTooManyRequests, res.StatusCode)
+		assert.NotEmpty(t, res.Header.Get("Retry-After"))
+	})
+
+	t.Run("Limits password attempts per client behind proxy", func(t *testing.T) {
+		attackerHeaders := map[string]string{
+			"Content-Type":    "application/x-www-form-urlencoded",
+			"X-Forwarded-For": "2.2.2.2",
+		}
+
+		for range 4 {
+			res, _ := testutils.SendTestRequest(
+				t, ts, client, http.MethodPost, "/locked", strings.NewReader("password=wrong"), attackerHeaders,
+			)
+			res.Body.Close()
+		}
+
+		res, _ := testutils.SendTestRequest(
+			t, ts, client, http.MethodPost, "/locked", strings.NewReader("password=passcode"),
+			map[string]string{
+				"Content-Type":    "application/x-www-form-urlencoded",
+				"X-Forwarded-For": "3.3.3.3",
+			},
+		)
+		defer res.Body.Close()
+
+		assert.Equal(t, http.StatusSeeOther, res.StatusCode)
+	})
+}
+
+//nolint:funlen
+func TestURLPreviewRoutes(t *testing.T) {
+	urlRepo := repository.NewURLMemoRepo()
+
+	ts := prepareTestServer(nil, urlRepo, nil)
+	defer ts.Close()
+
+	for _, url := range []*entity.URL{
+		{Short: "plain", Original: "https://a.ru/plain"},
+		{
+			Short:    "gate",
+			Original: "https://docs.a.ru/page",
+			URLSettings: entity.URLSettings{
+				Title:        "<b>Docs</b>",
+				Interstitial: true,
+				QueryPolicy:  entity.URLQueryOverride,
+			},
+		},
+		{
+			Short:       "locked",
+			Original:    "https://secret.a.ru",
+			URLSettings: entity.URLSettings{PasswordHash: "hash"},
+		},
+	} {
+		_, err := urlRepo.Store(context.Background(), url)
+		require.NoError(t, err)
+	}
+
+	testCases := []struct {
+		name             string
+		method           string
+		path             string
+		expectedCode     int
+		expectedLocation string
+		expectedContains []string
+		notContains      string
+	}{
+		{
+			name:             "Previews link by plus suffix",
+			method:           http.MethodGet,
+			path:             "/plain+",
+			expectedCode:     http.StatusOK,
+			expectedContains: []string{"<strong>a.ru</strong>", "https://a.ru/plain", `action="/plain"`},
+		},
+		{
+			name:             "Redirects link without interstitial",
+			method:           http.MethodGet,
+			path:             "/plain",
+			expectedCode:     http.StatusTemporaryRedirect,
+			expectedLocation: "https://a.ru/plain",
+		},
+		{
+			name:         "Shows interstitial instead of redirect",
+			method:       http.MethodGet,
+			path:         "/gate?ref=1",
+			expectedCode: http.StatusOK,
+			expectedContains: []string{
+				"<h1>&lt;b&gt;Docs&lt;/b&gt;</h1>",
+				"https://docs.a.ru/page?ref=1",
+				`action="/gate?ref=1"`,
+			},
+		},
+		{
+			name:             "Continues from interstitial",
+			method:           http.MethodPost,
+			path:             "/gate?ref=1",
+			expectedCode:     http.StatusSeeOther,
+			expectedLocation: "https://docs.a.ru/page?ref=1",
+		},
+		{
+			name:             "Hides destination of password protected link",
+			method:           http.MethodGet,
+			path:             "/locked+",
+			expectedCode:     http.StatusOK,
+			expectedContains: []string{`name="password"`},
+			notContains:      "secret.a.ru",
+		},
+		{
+			name:         "Fails preview of unknown link",
+			method:       http.MethodGet,
+			path:         "/unknown+",
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, body := testutils.SendTestRequest(t, ts, ts.Client(), tc.method, tc.path, http.NoBody, map[string]string{})
+			defer res.Body.Close()
+
+			assert.Equal(t, tc.expectedCode, res.StatusCode)
+			assert.Equal(t, tc.expectedLocation, res.Header.Get("Location"))
+
+			for _, expected := range tc.expectedContains {
+				assert.Contains(t, string(body), expected)
+			}
+
+			if tc.notContains != "" {
+				assert.NotContains(t, string(body), tc.notContains)
+			}
+		})
+	}
+}
+
+//nolint:funlen
+func TestURLQRRoutes(t *testing.T) {
+	urlRepo := repository.NewURLMemoRepo()
+
+	ts := prepareTestServer(nil, urlRepo, nil)
+	defer ts.Close()
+
+	for _, url := range []*entity.URL{
+		{Short: "a", Original: "https://a.ru", UserUUID: testutils.UserUUID},
+		{Short: "foreign", Original: "https://b.ru", UserUUID: "another-uuid"},
+	} {
+		_, err := urlRepo.Store(context.Background(), url)
+		require.NoError(t, err)
+	}
+
+	testCases := []struct {
+		name                string
+		path                string
+		expectedCode        int
+		expectedContentType string
+		expectedCache       string
+		expectedSize        int
+	}{
+		{
+			name:                "Renders png by default",
+			path:                "/a/qr",
+			expectedCode:        http.StatusOK,
+			expectedContentType: "image/png",
+			expectedCache:       "public, max-age=86400",
+			expectedSize:        256,
+		},
+		{
+			name:                "Renders png of requested size",
+			path:                "/a/qr?size=100&margin=0&level=H",
+			expectedCode:        http.StatusOK,
+			expectedContentType: "image/png",
+			expectedCache:       "public, max-age=86400",
+			expectedSize:        100,
+		},
+		{
+			name:                "Renders svg",
+			path:                "/a/qr?format=svg",
+			expectedCode:        http.StatusOK,
+			expectedContentType: "image/svg+xml",
+			expectedCache:       "public, max-age=86400",
+		},
+		{
+			name:         "Rejects too small size",
+			path:         "/a/qr?size=10",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "Rejects unknown level",
+			path:         "/a/qr?level=X",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "Rejects unknown format",
+			path:         "/a/qr?format=gif",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "Fails for unknown url",
+			path:         "/unknown/qr",
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:                "Renders user's url",
+			path:                "/api/user/urls/a/qr",
+			expectedCode:        http.StatusOK,
+			expectedContentType: "image/png",
+			expectedCache:       "private, max-age=86400",
+			expectedSize:        256,
+		},
+		{
+			name:         "Hides another user's url",
+			path:         "/api/user/urls/foreign/qr",
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	client := testutils.AuthorizedClient(t, ts)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, body := testutils.SendTestRequest(t, ts, client, http.MethodGet, tc.path, http.NoBody, map[string]string{})
+			defer res.Body.Close()
+
+			assert.Equal(t, tc.expectedCode, res.StatusCode)
+
+			if tc.expectedCode != http.StatusOK {
+				return
+			}
+
+			assert.Equal(t, tc.expectedContentType, res.Header.Get("Content-Type"))
+			assert.Equal(t, tc.expectedCache, res.Header.Get("Cache-Control"))
+
+			if tc.expectedSize != 0 {
+				img, err := png.Decode(bytes.NewReader(body))
+				require.NoError(t, err)
+
+				assert.Equal(t, tc.expectedSize, img.Bounds().Dx())
+			}
+		})
+	}
+}
+
+//nolint:funlen
+func TestURLRedirectCaching(t *testing.T) {
+	urlRepo := repository.NewURLMemoRepo()
+	clickRepo := repository.NewURLClickMemoRepo(100)
+
+	ts := prepareTestServer(nil, urlRepo, nil, withUseCaseOptions(
+		usecase.RedirectCacheMaxAge(time.Hour),
+		usecase.ClickAnalytics(clickRepo),
+	))
+	defer ts.Close()
+
+	permanent := entity.URLSettings{RedirectCode: http.StatusPermanentRedirect}
+	tracked := permanent
+	tracked.Variants = []entity.URLVariant{{Name: "a", Destination: "https://a.ru/a", Weight: 1}}
+
+	for _, url := range []*entity.URL{
+		{Short: "permanent", Original: "https://a.ru", URLSettings: permanent},
+		{Short: "tracked", Original: "https://a.ru", URLSettings: tracked},
+		{Short: "temporary", Original: "https://a.ru"},
+	} {
+		_, err := urlRepo.Store(context.Background(), url)
+		require.NoError(t, err)
+	}
+
+	res, _ := testutils.SendTestRequest(t, ts, ts.Client(), http.MethodGet, "/permanent", http.NoBody, map[string]string{})
+	defer res.Body.Close()
+
+	etag := res.Header.Get("ETag")
+
+	require.Equal(t, http.StatusPermanentRedirect, res.StatusCode)
+	require.NotEmpty(t, etag)
+	assert.Equal(t, "public, max-age=3600", res.Header.Get("Cache-Control"))
+
+	testCases := []struct {
+		name          string
+		method        string
+		path          string
+		headers       map[string]string
+		expectedCode  int
+		expectedCache string
+	}{
+		{
+			name:          "Revalidates permanent redirect",
+			method:        http.MethodGet,
+			path:          "/permanent",
+			headers:       map[string]string{"If-None-Match": `"other", ` + etag},
+			expectedCode:  http.StatusNotModified,
+			expectedCache: "public, max-age=3600",
+		},
+		{
+			name:          "Caches permanent redirect for head request",
+			method:        http.MethodHead,
+			path:          "/permanent",
+			expectedCode:  http.StatusPermanentRedirect,
+			expectedCache: "public, max-age=3600",
+		},
+		{
+			name:          "Does not cache tracked permanent redirect",
+			method:        http.MethodGet,
+			path:          "/tracked",
+			headers:       map[string]string{"If-None-Match": "*"},
+			expectedCode:  http.StatusPermanentRedirect,
+			expectedCache: "no-store",
+		},
+		{
+			name:          "Does not cache tracked redirect for head request",
+			method:        http.MethodHead,
+			path:          "/tracked",
+			expectedCode:  http.StatusPermanentRedirect,
+			expectedCache: "no-store",
+		},
+		{
+			name:          "Does not cache temporary redirect",
+			method:        http.MethodHead,
+			path:          "/temporary",
+			expectedCode:  http.StatusTemporaryRedirect,
+			expectedCache: "no-store",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, _ := testutils.SendTestRequest(t, ts, ts.Client(), tc.method, tc.path, http.NoBody, tc.headers)
+			defer res.Body.Close()
+
+			assert.Equal(t, tc.expectedCode, res.StatusCode)
+			assert.Equal(t, tc.expectedCache, res.Header.Get("Cache-Control"))
+		})
+	}
+
+	t.Run("Counts only get requests to links with variants", func(t *testing.T) {
+		stats, err := clickRepo.GetClickStats(context.Background(), "tracked")
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(1), stats.Total)
+
+		stats, err = clickRepo.GetClickStats(context.Background(), "permanent")
+		require.NoError(t, err)
+
+		assert.Zero(t, stats.Total)
+	})
+}
+
+//nolint:funlen
+func TestURLLinkErrors(t *testing.T) {
+	urlRepo := repository.NewURLMemoRepo()
+	expiredAt := time.Now().Add(-time.Minute)
+
+	for _, url := range []*entity.URL{
+		{Short: "deleted", Original: "https://a.ru", Deleted: true},
+		{Short: "disabled", Original: "https://a.ru", URLSettings: entity.URLSettings{Disabled: true}},
+		{Short: "expired", Original: "https://a.ru", URLSettings: entity.URLSettings{ExpiresAt: &expiredAt}},
+	} {
+		_, err := urlRepo.Store(context.Background(), url)
+		require.NoError(t, err)
+	}
+
+	themeDir := t.TempDir()
+	require.NoError(t, os.WriteFile(
+		filepath.Join(themeDir, "error_layout.html"),
+		[]byte(`{{define "error_page"}}themed {{.Code}}: {{.Title}}{{end}}`),
+		0o600,
+	))
+	require.NoError(t, os.WriteFile(
+		filepath.Join(themeDir, "expired.html"),
+		[]byte(`custom expired page for {{.Hash}}`),
+		0o600,
+	))
+
+	templates, err := rest.LoadTemplates(themeDir)
+	require.NoError(t, err)
+
+	ts := prepareTestServer(nil, urlRepo, nil)
+	defer ts.Close()
+
+	themed := prepareTestServer(nil, urlRepo, nil, withRoutesOptions(rest.Templates(templates)))
+	defer themed.Close()
+
+	const browserAccept = "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8"
+
+	testCases := []struct {
+		name                string
+		server              *httptest.Server
+		path                string
+		accept              string
+		expectedCode        int
+		expectedContentType string
+		expectedBody        string
+	}{
+		{
+			name:                "Renders not found page for browser",
+			server:              ts,
+			path:                "/unknown",
+			accept:              browserAccept,
+			expectedCode:        http.StatusNotFound,
+			expectedContentType: "text/html; charset=utf-8",
+			expectedBody:        "<h1>Link not found</h1>",
+		},
+		{
+			name:                "Renders deleted page for browser",
+			server:              ts,
+			path:                "/deleted",
+			accept:              browserAccept,
+			expectedCode:        http.StatusGone,
+			expectedContentType: "text/html; charset=utf-8",
+			expectedBody:        "<h1>Link deleted</h1>",
+		},
+		{
+			name:                "Returns json for api client",
+			server:              ts,
+			path:                "/disabled",
+			accept:              "application/json",
+			expectedCode:        http.StatusForbidden,
+			expectedContentType: "application/json",
+			expectedBody: toJSON(t, rest.LinkErrorResponse{
+				Error:   "disabled",
+				Message: "short url has been disabled",
+			}),
+		},
+		{
+			name:                "Returns text without accept",
+			server:              ts,
+			path:                "/expired",
+			expectedCode:        http.StatusGone,
+			expectedContentType: "text/plain; charset=utf-8",
+			expectedBody:        "short url has expired\n",
+		},
+		{
+			name:                "Prefers json by quality",
+			server:              ts,
+			path:                "/unknown+",
+			accept:              "text/html;q=0.5, application/json",
+			expectedCode:        http.StatusNotFound,
+			expectedContentType: "application/json",
+			expectedBody:        toJSON(t, rest.LinkErrorResponse{Error: "not_found", Message: "short url not found"}),
+		},
+		{
+			name:                "Uses overridden layout",
+			server:              themed,
+			path:                "/deleted",
+			accept:              browserAccept,
+			expectedCode:        http.StatusGone,
+			expectedContentType: "text/html; charset=utf-8",
+			expectedBody:        "themed 410: Link deleted",
+		},
+		{
+			name:                "Uses overridden page",
+			server:              themed,
+			path:                "/expired",
+			accept:              browserAccept,
+			expectedCode:        http.StatusGone,
+			expectedContentType: "text/html; charset=utf-8",
+			expectedBody:        "custom expired page for expired",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, body := testutils.SendTestRequest(
+				t, tc.server, tc.server.Client(), http.MethodGet, tc.path, http.NoBody,
+				map[string]string{"Accept": tc.accept},
+			)
+			defer res.Body.Close()
+
+			assert.Equal(t, tc.expectedCode, res.StatusCode)
+			assert.Equal(t, tc.expectedContentType, res.Header.Get("Content-Type"))
+			assert.Contains(t, string(body), tc.expectedBody)
+		})
+	}
+}
+
+//nolint:funlen
+func TestURLStateRoute(t *testing.T) {
+	urlRepo := repository.NewURLMemoRepo()
+
+	for _, url := range []*entity.URL{
+		{Short: "own", Original: "https://a.ru", UserUUID: testutils.UserUUID},
+		{Short: "foreign", Original: "https://b.ru", UserUUID: "another-uuid"},
+	} {
+		_, err := urlRepo.Store(context.Background(), url)
+		require.NoError(t, err)
+	}
+
+	ts := prepareTestServer(nil, urlRepo, nil)
+	defer ts.Close()
+
+	client := testutils.AuthorizedClient(t, ts)
+	jsonHeaders := map[string]string{"Content-Type": "application/json"}
+
+	setDisabled := func(t *testing.T, path string, body string) *http.Response {
+		t.Helper()
+
+		res, _ := testutils.SendTestRequest(
+			t, ts, client, http.MethodPatch, path, strings.NewReader(body), jsonHeaders,
+		)
+		defer res.Body.Close()
+
+		return res
+	}
+
+	redirect := func(t *testing.T) *http.Response {
+		t.Helper()
+
+		res, _ := testutils.SendTestRequest(t, ts, ts.Client(), http.MethodGet, "/own", http.NoBody, map[string]string{})
+		defer res.Body.Close()
+
+		return res
+	}
+
+	t.Run("Disables own url", func(t *testing.T) {
+		assert.Equal(t, http.StatusNoContent, setDisabled(t, "/api/user/urls/own", `{"disabled":true}`).StatusCode)
+		assert.Equal(t, http.StatusForbidden, redirect(t).StatusCode)
+	})
+
+	t.Run("Enables own url", func(t *testing.T) {
+		assert.Equal(t, http.StatusNoContent, setDisabled(t, "/api/user/urls/own", `{"disabled":false}`).StatusCode)
+		assert.Equal(t, http.StatusTemporaryRedirect, redirect(t).StatusCode)
+	})
+
+	t.Run("Rejects request without state", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, setDisabled(t, "/api/user/urls/own", `{}`).StatusCode)
+	})
+
+	t.Run("Hides url of another user", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, setDisabled(t, "/api/user/urls/foreign", `{"disabled":true}`).StatusCode)
+	})
+}
+
+//nolint:funlen
+func TestURLScheduledRoutes(t *testing.T) {
+	urlRepo := repository.NewURLMemoRepo()
+	now := time.Now().Truncate(time.Second)
+	launch, later, past := now.Add(time.Hour), now.Add(2*time.Hour), now.Add(-time.Hour)
+
+	for _, url := range []*entity.URL{
+		{
+			Short:       "later",
+			Original:    "https://a.ru/later",
+			UserUUID:    testutils.UserUUID,
+			URLSettings: entity.URLSettings{ActiveFrom: &later},
+		},
+		{
+			Short:       "launch",
+			Original:    "https://a.ru/launch",
+			UserUUID:    testutils.UserUUID,
+			URLSettings: entity.URLSettings{ActiveFrom: &launch},
+		},
+		{
+			Short:       "live",
+			Original:    "https://a.ru/live",
+			UserUUID:    testutils.UserUUID,
+			URLSettings: entity.URLSettings{ActiveFrom: &past},
+		},
+		{
+			Short:       "foreign",
+			Original:    "https://b.ru",
+			UserUUID:    "another-uuid",
+			URLSettings: entity.URLSettings{ActiveFrom: &launch},
+		},
+	} {
+		_, err := urlRepo.Store(context.Background(), url)
+		require.NoError(t, err)
+	}
+
+	ts := prepareTestServer(nil, urlRepo, nil)
+	defer ts.Close()
+
+	unavailable := prepareTestServer(nil, urlRepo, nil,
+		withUseCaseOptions(usecase.NotActiveCode(http.StatusServiceUnavailable)),
+	)
+	defer unavailable.Close()
+
+	t.Run("Hides link before activation", func(t *testing.T) {
+		res, body := testutils.SendTestRequest(
+			t, ts, ts.Client(), http.MethodGet, "/launch", http.NoBody,
+			map[string]string{"Accept": "application/json"},
+		)
+		defer res.Body.Close()
+
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+		assert.Empty(t, res.Header.Get("Retry-After"))
+		assert.Equal(t, toJSON(t, rest.LinkErrorResponse{
+			Error:   "not_found",
+			Message: "short url not found",
+		}), string(body))
+	})
+
+	t.Run("Uses configured code before activation", func(t *testing.T) {
+		res, body := testutils.SendTestRequest(
+			t, unavailable, unavailable.Client(), http.MethodGet, "/launch", http.NoBody,
+			map[string]string{"Accept": "application/json"},
+		)
+		defer res.Body.Close()
+
+		assert.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+		assert.Equal(t, launch.UTC().Format(http.TimeFormat), res.Header.Get("Retry-After"))
+		assert.Equal(t, toJSON(t, rest.LinkErrorResponse{
+			Error:   "not_active",
+			Message: "short url is not active yet",
+		}), string(body))
+	})
+
+	t.Run("Redirects after activation", func(t *testing.T) {
+		res, _ := testutils.SendTestRequest(t, ts, ts.Client(), http.MethodGet, "/live", http.NoBody, map[string]string{})
+		defer res.Body.Close()
+
+		assert.Equal(t, http.StatusTemporaryRedirect, res.StatusCode)
+	})
+
+	t.Run("Lists user's scheduled links by activation time", func(t *testing.T) {
+		res, body := testutils.SendTestRequest(
+			t, ts, testutils.AuthorizedClient(t, ts), http.MethodGet, "/api/user/urls/scheduled", http.NoBody,
+			map[string]string{},
+		)
+		defer res.Body.Close()
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, toJSON(t, []rest.ScheduledURLItem{
+			{ActiveFrom: launch, ShortURL: "http://localhost:8080/launch", OriginalURL: "https://a.ru/launch"},
+			{ActiveFrom: later, ShortURL: "http://localhost:8080/later", OriginalURL: "https://a.ru/later"},
+		}), string(body))
 	})
 }
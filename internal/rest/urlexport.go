@@ -0,0 +1,210 @@
+package rest
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/llravell/go-shortener/internal/entity"
+)
+
+const (
+	exportContentTypeCSV    = "text/csv"
+	exportContentTypeJSON   = "application/json"
+	exportContentTypeNDJSON = "application/x-ndjson"
+)
+
+var exportFileExtensions = map[string]string{
+	exportContentTypeCSV:    "csv",
+	exportContentTypeJSON:   "json",
+	exportContentTypeNDJSON: "ndjson",
+}
+
+// URLExportItem dto урла пользователя в выгрузке.
+type URLExportItem struct {
+	CreatedAt   time.Time `json:"created_at"`
+	ShortURL    string    `json:"short_url"`
+	OriginalURL string    `json:"original_url"`
+	IsDeleted   bool      `json:"is_deleted"`
+}
+
+// urlExportWriter пишет выгрузку в одном из форматов по мере получения урлов из репозитория.
+type urlExportWriter interface {
+	Write(item *URLExportItem) error
+	Close() error
+}
+
+type jsonExportWriter struct {
+	w       io.Writer
+	encoder *json.Encoder
+	written bool
+}
+
+func newJSONExportWriter(w io.Writer) *jsonExportWriter {
+	return &jsonExportWriter{w: w, encoder: json.NewEncoder(w)}
+}
+
+func (jw *jsonExportWriter) Write(item *URLExportItem) error {
+	delimiter := ","
+	if !jw.written {
+		delimiter = "["
+		jw.written = true
+	}
+
+	if _, err := io.WriteString(jw.w, delimiter); err != nil {
+		return err
+	}
+
+	return jw.encoder.Encode(item)
+}
+
+func (jw *jsonExportWriter) Close() error {
+	closing := "]\n"
+	if !jw.written {
+		closing = "[]\n"
+	}
+
+	_, err := io.WriteString(jw.w, closing)
+
+	return err
+}
+
+type ndjsonExportWriter struct {
+	encoder *json.Encoder
+}
+
+func (nw *ndjsonExportWriter) Write(item *URLExportItem) error {
+	return nw.encoder.Encode(item)
+}
+
+func (nw *ndjsonExportWriter) Close() error {
+	return nil
+}
+
+// csvExportWriter пишет заголовок вместе с первой строкой или при закрытии,
+// чтобы до первого урла ответ еще можно было заменить ошибкой.
+type csvExportWriter struct {
+	writer        *csv.Writer
+	headerWritten bool
+}
+
+func (cw *csvExportWriter) writeHeader() error {
+	if cw.headerWritten {
+		return nil
+	}
+
+	cw.headerWritten = true
+
+	return cw.writer.Write([]string{"short_url", "original_url", "created_at", "is_deleted"})
+}
+
+func (cw *csvExportWriter) Write(item *URLExportItem) error {
+	if err := cw.writeHeader(); err != nil {
+		return err
+	}
+
+	return cw.writer.Write([]string{
+		item.ShortURL,
+		item.OriginalURL,
+		item.CreatedAt.Format(time.RFC3339),
+		strconv.FormatBool(item.IsDeleted),
+	})
+}
+
+func (cw *csvExportWriter) Close() error {
+	if err := cw.writeHeader(); err != nil {
+		return err
+	}
+
+	cw.writer.Flush()
+
+	return cw.writer.Error()
+}
+
+// newURLExportWriter создает писателя выгрузки. Писатели ничего не пишут до первого урла или закрытия.
+func newURLExportWriter(contentType string, w io.Writer) urlExportWriter {
+	switch contentType {
+	case exportContentTypeCSV:
+		return &csvExportWriter{writer: csv.NewWriter(w)}
+	case exportContentTypeNDJSON:
+		return &ndjsonExportWriter{encoder: json.NewEncoder(w)}
+	default:
+		return newJSONExportWriter(w)
+	}
+}
+
+// negotiateExportContentType выбирает формат выгрузки по заголовку Accept с учетом q-факторов.
+// Без заголовка выгрузка отдается в JSON.
+func negotiateExportContentType(accept string) (string, bool) {
+	if strings.TrimSpace(accept) == "" {
+		return exportContentTypeJSON, true
+	}
+
+	for _, mediaType := range parseAccept(accept) {
+		switch mediaType {
+		case exportContentTypeCSV, exportContentTypeJSON, exportContentTypeNDJSON:
+			return mediaType, true
+		case "*/*", "application/*":
+			return exportContentTypeJSON, true
+		case "text/*":
+			return exportContentTypeCSV, true
+		}
+	}
+
+	return "", false
+}
+
+// exportUserURLS выгружает урлы пользователя в CSV, JSON или NDJSON, читая их из репозитория потоком.
+// Начатую выгрузку нельзя превратить в ответ с ошибкой, поэтому при сбое после первой строки она обрывается.
+func (ur *URLRoutes) exportUserURLS(w http.ResponseWriter, r *http.Request) {
+	contentType, ok := negotiateExportContentType(r.Header.Get("Accept"))
+	if !ok {
+		http.Error(w, "Not acceptable", http.StatusNotAcceptable)
+
+		return
+	}
+
+	userUUID := ur.getUserUUIDFromRequest(r)
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set(
+		"Content-Disposition",
+		mime.FormatMediaType("attachment", map[string]string{
+			"filename": "urls." + exportFileExtensions[contentType],
+		}),
+	)
+
+	writer := newURLExportWriter(contentType, w)
+	written := 0
+
+	err := ur.urlUC.ExportUserURLs(r.Context(), userUUID, func(url *entity.URL) error {
+		written++
+
+		return writer.Write(&URLExportItem{
+			ShortURL:    ur.urlUC.BuildRedirectURL(url),
+			OriginalURL: url.Original,
+			CreatedAt:   url.CreatedAt,
+			IsDeleted:   url.Deleted,
+		})
+	})
+	if err != nil {
+		ur.log.Err(err).Str("userUUID", userUUID).Msg("urls export has been failed")
+
+		// до первого урла в ответ ничего не записано, поэтому его еще можно заменить ошибкой
+		if written == 0 {
+			w.Header().Del("Content-Disposition")
+			http.Error(w, "exporting urls failed", http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	if err = writer.Close(); err != nil {
+		ur.log.Err(err).Msg("response write has been failed")
+	}
+}
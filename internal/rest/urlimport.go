@@ -0,0 +1,175 @@
+package rest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+
+	"github.com/llravell/go-shortener/internal/entity"
+	"github.com/llravell/go-shortener/internal/usecase"
+)
+
+const (
+	importChunkSize   = 500
+	importMaxLineSize = 1 << 20
+	malformedLine     = "line is not a valid json object"
+)
+
+// URLImportResponseItem dto результата импорта одной строки.
+// Номер строки позволяет клиенту продолжить прерванный импорт со следующей строки.
+type URLImportResponseItem struct {
+	Line int `json:"line"`
+	URLBatchResponseItem
+}
+
+// urlImportChunk накопленные строки импорта, которые сохраняются одним запросом.
+type urlImportChunk struct {
+	lines []int
+	items []URLBatchRequestItem
+}
+
+func (c *urlImportChunk) add(line int, item URLBatchRequestItem) {
+	c.lines = append(c.lines, line)
+	c.items = append(c.items, item)
+}
+
+func (c *urlImportChunk) reset() {
+	c.lines = c.lines[:0]
+	c.items = c.items[:0]
+}
+
+// importURLs принимает урлы в формате NDJSON и сохраняет их пачками, не читая тело целиком.
+// Результат по каждой непустой строке сразу отдается клиенту тоже в формате NDJSON.
+func (ur *URLRoutes) importURLs(w http.ResponseWriter, r *http.Request) {
+	userUUID := ur.getUserUUIDFromRequest(r)
+	if userUUID == "" {
+		w.WriteHeader(http.StatusUnauthorized)
+
+		return
+	}
+
+	rc := http.NewResponseController(w)
+
+	// Без полнодуплексного режима HTTP/1 сервер не дает читать тело после начала ответа.
+	if err := rc.EnableFullDuplex(); err != nil {
+		ur.log.Debug().Err(err).Msg("full duplex is not supported")
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+
+	scanner := bufio.NewScanner(r.Body)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), importMaxLineSize)
+
+	encoder := json.NewEncoder(w)
+	chunk := &urlImportChunk{}
+	line := 0
+
+	for scanner.Scan() {
+		line++
+
+		raw := bytes.TrimSpace(scanner.Bytes())
+		if len(raw) == 0 {
+			continue
+		}
+
+		var item URLBatchRequestItem
+
+		if err := json.Unmarshal(raw, &item); err != nil {
+			if !ur.flushImportChunk(r, encoder, rc, chunk, userUUID) {
+				return
+			}
+
+			ok := ur.writeImportItem(encoder, URLImportResponseItem{
+				Line: line,
+				URLBatchResponseItem: URLBatchResponseItem{
+					Status: entity.URLStoreInvalid,
+					Reason: malformedLine,
+				},
+			})
+			if !ok {
+				return
+			}
+
+			continue
+		}
+
+		chunk.add(line, item)
+
+		if len(chunk.items) >= importChunkSize && !ur.flushImportChunk(r, encoder, rc, chunk, userUUID) {
+			return
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		ur.log.Err(err).Int("line", line+1).Msg("import reading has been failed")
+	}
+
+	ur.flushImportChunk(r, encoder, rc, chunk, userUUID)
+}
+
+// flushImportChunk сохраняет накопленные строки и отправляет клиенту их результаты.
+// Возвращает false, если импорт нужно прервать.
+func (ur *URLRoutes) flushImportChunk(
+	r *http.Request,
+	encoder *json.Encoder,
+	rc *http.ResponseController,
+	chunk *urlImportChunk,
+	userUUID string,
+) bool {
+	if len(chunk.items) == 0 {
+		return true
+	}
+
+	defer chunk.reset()
+
+	drafts := make([]*entity.URLDraft, 0, len(chunk.items))
+	for i := range chunk.items {
+		drafts = append(drafts, chunk.items[i].draft())
+	}
+
+	results, err := ur.urlUC.SaveURLMultiple(r.Context(), drafts, userUUID, usecase.URLBatchBestEffort)
+	if err != nil {
+		ur.log.Err(err).
+			Int("line", chunk.lines[0]).
+			Msg("import chunk saving has been failed")
+
+		return false
+	}
+
+	for i, result := range results {
+		item := URLImportResponseItem{
+			Line: chunk.lines[i],
+			URLBatchResponseItem: URLBatchResponseItem{
+				CorrelationID: chunk.items[i].CorrelationID,
+				Status:        result.Status,
+				Reason:        result.Reason,
+			},
+		}
+
+		if result.URL != nil {
+			item.ShortURL = ur.urlUC.BuildRedirectURL(result.URL)
+		}
+
+		if !ur.writeImportItem(encoder, item) {
+			return false
+		}
+	}
+
+	if err = rc.Flush(); err != nil {
+		ur.log.Debug().Err(err).Msg("import flushing is not supported")
+	}
+
+	return true
+}
+
+func (ur *URLRoutes) writeImportItem(encoder *json.Encoder, item URLImportResponseItem) bool {
+	if err := encoder.Encode(item); err != nil {
+		ur.log.Err(err).Msg("response write has been failed")
+
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,72 @@
+package rest
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/llravell/go-shortener/internal/usecase"
+)
+
+const maxPasswordFormSize = 4 << 10
+
+type passwordPage struct {
+	Action  string
+	Message string
+}
+
+func (ur *URLRoutes) renderPasswordForm(
+	w http.ResponseWriter,
+	r *http.Request,
+	hash string,
+	code int,
+	message string,
+) {
+	ur.renderPage(w, code, "password.html", passwordPage{
+		Action:  unlockAction(r, hash),
+		Message: message,
+	})
+}
+
+// unlockURL проверяет пароль из формы и перенаправляет на урл назначения ссылки.
+// После POST всегда используется 303, чтобы браузер не отправил пароль повторно по новому адресу.
+func (ur *URLRoutes) unlockURL(w http.ResponseWriter, r *http.Request) {
+	url, ok := ur.resolveActiveURL(w, r)
+	if !ok {
+		return
+	}
+
+	if !url.HasPassword() {
+		ur.redirect(w, r, url, http.StatusSeeOther)
+
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxPasswordFormSize)
+
+	if err := r.ParseForm(); err != nil {
+		ur.renderPasswordForm(w, r, url.Short, http.StatusBadRequest, "Invalid form.")
+
+		return
+	}
+
+	err := ur.urlUC.VerifyPassword(url, clientIP(r), r.PostForm.Get("password"))
+	if err != nil {
+		var attemptsErr *usecase.URLPasswordAttemptsError
+
+		switch {
+		case errors.As(err, &attemptsErr):
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(attemptsErr.RetryAfter.Seconds()))))
+			ur.renderPasswordForm(w, r, url.Short, http.StatusTooManyRequests, "Too many attempts. Try again later.")
+		case errors.Is(err, usecase.ErrURLPasswordInvalid):
+			ur.renderPasswordForm(w, r, url.Short, http.StatusUnauthorized, "Wrong password.")
+		default:
+			http.Error(w, "password verification failed", http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	ur.redirect(w, r, url, http.StatusSeeOther)
+}
@@ -0,0 +1,58 @@
+package rest
+
+import (
+	"net/http"
+	neturl "net/url"
+
+	"github.com/llravell/go-shortener/internal/entity"
+)
+
+type previewPage struct {
+	Title       string
+	Host        string
+	Destination string
+	Action      string
+}
+
+// unlockAction адрес формы перехода по ссылке, параметры исходного запроса сохраняются.
+func unlockAction(r *http.Request, hash string) string {
+	action := neturl.URL{Path: "/" + hash, RawQuery: r.URL.RawQuery}
+
+	return action.String()
+}
+
+// renderPreview показывает урл назначения ссылки и кнопку перехода.
+// Переход выполняется через POST, поэтому учитывается так же, как обычный редирект.
+func (ur *URLRoutes) renderPreview(w http.ResponseWriter, r *http.Request, url *entity.URL) {
+	destination := ur.urlUC.BuildDestinationURL(url, newVisit(r))
+
+	page := previewPage{
+		Title:       url.Title,
+		Host:        destination.URL,
+		Destination: destination.URL,
+		Action:      unlockAction(r, url.Short),
+	}
+
+	if parsed, err := neturl.Parse(destination.URL); err == nil && parsed.Host != "" {
+		page.Host = parsed.Host
+	}
+
+	ur.renderPage(w, http.StatusOK, "preview.html", page)
+}
+
+// previewURL показывает, куда ведет ссылка, не выполняя редирект.
+// Для ссылки с паролем урл назначения не раскрывается и показывается форма пароля.
+func (ur *URLRoutes) previewURL(w http.ResponseWriter, r *http.Request) {
+	url, ok := ur.resolveActiveURL(w, r)
+	if !ok {
+		return
+	}
+
+	if url.HasPassword() {
+		ur.renderPasswordForm(w, r, url.Short, http.StatusOK, "")
+
+		return
+	}
+
+	ur.renderPreview(w, r, url)
+}
@@ -0,0 +1,156 @@
+package rest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/llravell/go-shortener/internal/entity"
+	"github.com/llravell/go-shortener/internal/usecase"
+	"github.com/llravell/go-shortener/pkg/qr"
+)
+
+const (
+	qrFormatPNG = "png"
+	qrFormatSVG = "svg"
+
+	qrDefaultSize   = 256
+	qrMinSize       = 64
+	qrMaxSize       = 2048
+	qrDefaultMargin = 4
+	qrMaxMargin     = 16
+	qrDefaultLevel  = "M"
+	qrCacheMaxAge   = 86400
+)
+
+var qrContentTypes = map[string]string{
+	qrFormatPNG: "image/png",
+	qrFormatSVG: "image/svg+xml",
+}
+
+// qrParams параметры отрисовки QR кода из запроса.
+type qrParams struct {
+	format string
+	level  string
+	size   int
+	margin int
+}
+
+func parseQRInt(raw string, defaultValue, minValue, maxValue int, name string) (int, error) {
+	if raw == "" {
+		return defaultValue, nil
+	}
+
+	value, err := strconv.Atoi(raw)
+	if err != nil || value < minValue || value > maxValue {
+		return 0, fmt.Errorf("%s must be an integer from %d to %d", name, minValue, maxValue)
+	}
+
+	return value, nil
+}
+
+func parseQRParams(r *http.Request) (*qrParams, error) {
+	query := r.URL.Query()
+	params := &qrParams{
+		format: query.Get("format"),
+		level:  query.Get("level"),
+	}
+
+	if params.format == "" {
+		params.format = qrFormatPNG
+	}
+
+	if _, ok := qrContentTypes[params.format]; !ok {
+		return nil, errors.New("format must be png or svg")
+	}
+
+	if params.level == "" {
+		params.level = qrDefaultLevel
+	}
+
+	var err error
+
+	params.size, err = parseQRInt(query.Get("size"), qrDefaultSize, qrMinSize, qrMaxSize, "size")
+	if err != nil {
+		return nil, err
+	}
+
+	params.margin, err = parseQRInt(query.Get("margin"), qrDefaultMargin, 0, qrMaxMargin, "margin")
+	if err != nil {
+		return nil, err
+	}
+
+	return params, nil
+}
+
+// writeQRCode отдает QR код короткой ссылки. Код рисуется целиком в буфер,
+// чтобы ошибка не оборвала уже начатый ответ.
+func (ur *URLRoutes) writeQRCode(w http.ResponseWriter, r *http.Request, url *entity.URL, cacheControl string) {
+	params, err := parseQRParams(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	code, err := qr.New(ur.urlUC.BuildRedirectURL(url), params.level)
+	if err != nil {
+		if errors.Is(err, qr.ErrLevelUnknown) {
+			http.Error(w, "level must be one of L, M, Q, H", http.StatusBadRequest)
+		} else {
+			http.Error(w, "qr code encoding failed", http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	var buf bytes.Buffer
+
+	if params.format == qrFormatSVG {
+		err = code.SVG(&buf, params.size, params.margin)
+	} else {
+		err = code.PNG(&buf, params.size, params.margin)
+	}
+
+	if err != nil {
+		ur.log.Err(err).Str("hash", url.Short).Msg("qr code rendering has been failed")
+		http.Error(w, "qr code rendering failed", http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", qrContentTypes[params.format])
+	w.Header().Set("Cache-Control", cacheControl)
+
+	if _, err = buf.WriteTo(w); err != nil {
+		ur.log.Err(err).Msg("response write has been failed")
+	}
+}
+
+// getQRCode публичный QR код ссылки.
+func (ur *URLRoutes) getQRCode(w http.ResponseWriter, r *http.Request) {
+	url, ok := ur.resolveActiveURL(w, r)
+	if !ok {
+		return
+	}
+
+	ur.writeQRCode(w, r, url, "public, max-age="+strconv.Itoa(qrCacheMaxAge))
+}
+
+// getUserQRCode QR код ссылки владельца, доступен и для удаленной ссылки.
+func (ur *URLRoutes) getUserQRCode(w http.ResponseWriter, r *http.Request) {
+	url, err := ur.urlUC.GetUserURL(r.Context(), ur.getUserUUIDFromRequest(r), r.PathValue(`id`))
+	if err != nil {
+		if errors.Is(err, usecase.ErrURLNotFound) {
+			http.Error(w, "url not found", http.StatusNotFound)
+		} else {
+			http.Error(w, "searching url failed", http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	ur.writeQRCode(w, r, url, "private, max-age="+strconv.Itoa(qrCacheMaxAge))
+}
@@ -2,20 +2,39 @@ package usecase
 
 import (
 	"context"
+	"net"
+	"time"
 
 	"github.com/llravell/go-shortener/internal/entity"
 )
 
 // Интерфейсы сторонних зависимостей.
 //
-//go:generate ../../bin/mockgen -destination=../mocks/mock_usecase.go -package=mocks . URLRepo,HealthRepo,HashGenerator
+//go:generate ../../bin/mockgen -destination=../mocks/mock_usecase.go -package=mocks . URLRepo,URLDeleteJobRepo,ClickRepo,HealthRepo,HashGenerator,GeoLocator
 type (
 	URLRepo interface {
 		Store(ctx context.Context, url *entity.URL) (*entity.URL, error)
-		StoreMultipleURLs(ctx context.Context, urls []*entity.URL) error
+		StoreMultipleURLs(ctx context.Context, urls []*entity.URL) ([]*entity.URLStoreResult, error)
 		GetURL(ctx context.Context, hash string) (*entity.URL, error)
 		GetUserURLS(ctx context.Context, userUUID string) ([]*entity.URL, error)
-		DeleteMultipleURLs(ctx context.Context, userUUID string, urlHashes []string) error
+		GetUserScheduledURLs(ctx context.Context, userUUID string, now time.Time) ([]*entity.URL, error)
+		IterateUserURLs(ctx context.Context, userUUID string, fn func(url *entity.URL) error) error
+		DeleteMultipleURLs(ctx context.Context, userUUID string, urlHashes []string) ([]*entity.URLDeleteResult, error)
+		SetURLDisabled(ctx context.Context, userUUID string, hash string, disabled bool) error
+	}
+
+	URLDeleteJobRepo interface {
+		Store(ctx context.Context, job *entity.URLDeleteJob) error
+		Update(ctx context.Context, job *entity.URLDeleteJob) error
+		Get(ctx context.Context, id string) (*entity.URLDeleteJob, error)
+		ClaimExpired(ctx context.Context, owner string, lease time.Duration, limit int) ([]*entity.URLDeleteJob, error)
+		ExtendLeases(ctx context.Context, owner string, lease time.Duration) error
+		DeleteFinished(ctx context.Context, before time.Time) (int, error)
+	}
+
+	ClickRepo interface {
+		StoreClick(ctx context.Context, click *entity.URLClick) error
+		GetClickStats(ctx context.Context, short string) (*entity.URLClickStats, error)
 	}
 
 	HealthRepo interface {
@@ -25,4 +44,8 @@ type (
 	HashGenerator interface {
 		Generate() (string, error)
 	}
+
+	GeoLocator interface {
+		Country(ip net.IP) (string, error)
+	}
 )
@@ -4,80 +4,309 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
+	neturl "net/url"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
 
+	"github.com/google/uuid"
 	"github.com/rs/zerolog"
 
 	"github.com/llravell/go-shortener/internal/entity"
 	"github.com/llravell/go-shortener/internal/repo"
+	"github.com/llravell/go-shortener/pkg/ratelimit"
+	"github.com/llravell/go-shortener/pkg/workerpool"
 )
 
 // ErrURLDuplicate ошибка создания дубля.
 var ErrURLDuplicate = errors.New("duplicate url")
 
+// ErrURLDeleteJobNotFound ошибка поиска задачи удаления.
+var ErrURLDeleteJobNotFound = errors.New("url delete job not found")
+
+// ErrURLDeleteQueueFull ошибка переполнения очереди удаления.
+var ErrURLDeleteQueueFull = errors.New("url delete queue is full")
+
+// ErrURLInvalid ошибка недопустимого адреса для сокращения.
+var ErrURLInvalid = errors.New("invalid url")
+
+// ErrURLSettingsInvalid ошибка недопустимых настроек ссылки.
+var ErrURLSettingsInvalid = errors.New("invalid url settings")
+
+var (
+	// ErrRedirectCodeInvalid ошибка недопустимого кода редиректа.
+	ErrRedirectCodeInvalid = fmt.Errorf("%w: redirect code must be one of 301, 302, 303, 307, 308", ErrURLSettingsInvalid)
+	// ErrQueryPolicyInvalid ошибка неизвестного правила переноса параметров запроса.
+	ErrQueryPolicyInvalid = fmt.Errorf(
+		"%w: query policy must be one of drop, keep-existing, override",
+		ErrURLSettingsInvalid,
+	)
+	// ErrUTMInvalid ошибка недопустимых UTM меток.
+	ErrUTMInvalid = fmt.Errorf("%w: utm keys must be utm_* parameters with non-empty values", ErrURLSettingsInvalid)
+	// ErrExpiresAtInvalid ошибка срока действия ссылки в прошлом.
+	ErrExpiresAtInvalid = fmt.Errorf("%w: expires_at must be in the future", ErrURLSettingsInvalid)
+	// ErrActiveFromInvalid ошибка времени активации позже срока действия ссылки.
+	ErrActiveFromInvalid = fmt.Errorf("%w: active_from must be before expires_at", ErrURLSettingsInvalid)
+	// ErrTitleTooLong ошибка слишком длинного заголовка ссылки.
+	ErrTitleTooLong = fmt.Errorf("%w: title must be at most %d characters", ErrURLSettingsInvalid, maxURLTitleLength)
+)
+
+// URLBatchMode режим сохранения пачки урлов.
+type URLBatchMode string
+
+const (
+	// URLBatchAtomic пачка отклоняется целиком, если хотя бы один урл невалиден.
+	URLBatchAtomic URLBatchMode = "atomic"
+	// URLBatchBestEffort сохраняются все валидные урлы пачки.
+	URLBatchBestEffort URLBatchMode = "best-effort"
+)
+
+const (
+	maxURLLength        = 2048
+	maxURLTitleLength   = 200
+	defaultRedirectCode = http.StatusTemporaryRedirect
+	// До запуска ссылка по умолчанию выглядит несуществующей, чтобы не раскрывать кампанию заранее.
+	defaultNotActiveCode = http.StatusNotFound
+)
+
+const (
+	// Аренда задачи удаления должна заметно превышать интервал WatchDeleteJobs, который ее продлевает.
+	deleteJobLease         = time.Minute
+	deleteJobRetention     = 24 * time.Hour // единый срок хранения завершенных задач для всех репозиториев
+	deleteJobResumeBatch   = 100
+	deleteJobResumeTimeout = time.Second * 30
+)
+
 // URLDeleteWorkerPool пул, обрабатывающий удаление урлов.
 type URLDeleteWorkerPool interface {
-	QueueWork(w *URLDeleteWork) error
+	QueueWorkContext(ctx context.Context, w *URLDeleteWork) error
+	TryQueueWork(w *URLDeleteWork) error
 }
 
 // URLDeleteWork задача удаления урлов.
 type URLDeleteWork struct {
 	repo     URLRepo
+	jobRepo  URLDeleteJobRepo
 	log      *zerolog.Logger
+	job      *entity.URLDeleteJob
+	JobID    string
 	UserUUID string
 	Hashes   []string
 }
 
-// Do удаляет урлы пользователя.
-func (w *URLDeleteWork) Do(ctx context.Context) {
-	err := w.repo.DeleteMultipleURLs(ctx, w.UserUUID, w.Hashes)
+func (w *URLDeleteWork) updateJob(ctx context.Context, status entity.URLDeleteJobStatus) {
+	w.job.Status = status
+	w.job.UpdatedAt = time.Now()
+
+	err := w.jobRepo.Update(ctx, w.job)
+	if err != nil {
+		w.log.Error().
+			Err(err).
+			Str("jobID", w.JobID).
+			Msg("delete job update failed")
+	}
+}
+
+// Do удаляет урлы пользователя, фиксируя ход выполнения в задаче.
+// При ошибке задача возвращается в статус ожидания, чтобы пул мог повторить попытку.
+func (w *URLDeleteWork) Do(ctx context.Context) error {
+	w.updateJob(ctx, entity.URLDeleteJobRunning)
+
+	results, err := w.repo.DeleteMultipleURLs(ctx, w.UserUUID, w.Hashes)
 	if err != nil {
 		w.log.Error().
 			Err(err).
 			Str("userUUID", w.UserUUID).
+			Str("jobID", w.JobID).
 			Msg("delete urls failed")
 
-		return
+		w.job.Error = err.Error()
+		w.updateJob(ctx, entity.URLDeleteJobQueued)
+
+		return err
 	}
 
 	w.log.Info().
 		Str("userUUID", w.UserUUID).
+		Str("jobID", w.JobID).
 		Msg("delete urls successeded")
+
+	w.job.Error = ""
+	w.job.Results = results
+	w.updateJob(ctx, entity.URLDeleteJobSucceeded)
+
+	return nil
+}
+
+// Fail помечает задачу проваленной, когда повторные попытки исчерпаны.
+func (w *URLDeleteWork) Fail(ctx context.Context, err error) {
+	w.job.Error = err.Error()
+	w.updateJob(ctx, entity.URLDeleteJobFailed)
 }
 
 // URLUseCase юзкейс базовых операций с урлами.
 type URLUseCase struct {
-	repo            URLRepo
-	wp              URLDeleteWorkerPool
-	gen             HashGenerator
-	log             zerolog.Logger
-	baseRedirectURL string
+	repo                URLRepo
+	jobRepo             URLDeleteJobRepo
+	wp                  URLDeleteWorkerPool
+	gen                 HashGenerator
+	log                 zerolog.Logger
+	geo                 GeoLocator
+	clicks              ClickRepo
+	passwordLimiter     *ratelimit.Limiter
+	baseRedirectURL     string
+	defaultRedirectCode int
+	redirectCacheMaxAge time.Duration
+	notActiveCode       int
+	instanceID          string
+}
+
+// URLUseCaseOption дополнительная опция юзкейса.
+type URLUseCaseOption func(uc *URLUseCase)
+
+// DefaultRedirectCode задает код редиректа для ссылок, созданных без собственного кода.
+func DefaultRedirectCode(code int) URLUseCaseOption {
+	return func(uc *URLUseCase) {
+		uc.defaultRedirectCode = code
+	}
+}
+
+// RedirectCacheMaxAge задает время кэширования постоянных редиректов, 0 отключает кэширование.
+func RedirectCacheMaxAge(maxAge time.Duration) URLUseCaseOption {
+	return func(uc *URLUseCase) {
+		uc.redirectCacheMaxAge = maxAge
+	}
+}
+
+// NotActiveCode задает код ответа для ссылки, время активации которой еще не наступило.
+func NotActiveCode(code int) URLUseCaseOption {
+	return func(uc *URLUseCase) {
+		uc.notActiveCode = code
+	}
+}
+
+// InstanceID задает идентификатор инстанса, от имени которого арендуются задачи удаления.
+func InstanceID(id string) URLUseCaseOption {
+	return func(uc *URLUseCase) {
+		uc.instanceID = id
+	}
+}
+
+// GeoLocation подключает определение страны посетителя для правил редиректа.
+func GeoLocation(geo GeoLocator) URLUseCaseOption {
+	return func(uc *URLUseCase) {
+		uc.geo = geo
+	}
+}
+
+// ClickAnalytics подключает сохранение переходов по ссылкам.
+func ClickAnalytics(clicks ClickRepo) URLUseCaseOption {
+	return func(uc *URLUseCase) {
+		uc.clicks = clicks
+	}
+}
+
+// PasswordAttempts задает число попыток ввода пароля ссылки за период.
+func PasswordAttempts(limit int, period time.Duration) URLUseCaseOption {
+	return func(uc *URLUseCase) {
+		uc.passwordLimiter = ratelimit.New(limit, period)
+	}
 }
 
 // NewURLUseCase создает юзкейс.
 func NewURLUseCase(
 	repo URLRepo,
+	jobRepo URLDeleteJobRepo,
 	wp URLDeleteWorkerPool,
 	gen HashGenerator,
 	baseRedirectURL string,
 	log zerolog.Logger,
+	opts ...URLUseCaseOption,
 ) *URLUseCase {
-	return &URLUseCase{
-		repo:            repo,
-		wp:              wp,
-		gen:             gen,
-		log:             log,
-		baseRedirectURL: baseRedirectURL,
+	uc := &URLUseCase{
+		repo:                repo,
+		jobRepo:             jobRepo,
+		wp:                  wp,
+		gen:                 gen,
+		log:                 log,
+		baseRedirectURL:     baseRedirectURL,
+		defaultRedirectCode: defaultRedirectCode,
+		notActiveCode:       defaultNotActiveCode,
+		passwordLimiter:     ratelimit.New(defaultPasswordAttemptsLimit, defaultPasswordAttemptsPeriod),
+		instanceID:          uuid.New().String(),
+	}
+
+	for _, opt := range opts {
+		opt(uc)
+	}
+
+	return uc
+}
+
+func validateSettings(settings *entity.URLSettings) error {
+	if settings.RedirectCode != 0 && !entity.IsRedirectCode(settings.RedirectCode) {
+		return ErrRedirectCodeInvalid
+	}
+
+	switch settings.QueryPolicy {
+	case "", entity.URLQueryDrop, entity.URLQueryKeepExisting, entity.URLQueryOverride:
+	default:
+		return ErrQueryPolicyInvalid
+	}
+
+	if settings.ExpiresAt != nil && !settings.ExpiresAt.After(time.Now()) {
+		return ErrExpiresAtInvalid
+	}
+
+	if settings.ActiveFrom != nil && settings.ExpiresAt != nil && !settings.ActiveFrom.Before(*settings.ExpiresAt) {
+		return ErrActiveFromInvalid
+	}
+
+	if utf8.RuneCountInString(settings.Title) > maxURLTitleLength {
+		return ErrTitleTooLong
+	}
+
+	for key, value := range settings.UTM {
+		if !slices.Contains(entity.UTMParams, key) || value == "" {
+			return ErrUTMInvalid
+		}
 	}
+
+	if err := validateRules(settings.Rules); err != nil {
+		return err
+	}
+
+	return validateVariants(settings.Variants)
 }
 
-// SaveURL сохраняет урл.
-func (uc *URLUseCase) SaveURL(ctx context.Context, url string, userUUID string) (*entity.URL, error) {
+// SaveURL сохраняет урл. Урл проверяется так же, как при пакетном сохранении и импорте.
+func (uc *URLUseCase) SaveURL(ctx context.Context, draft *entity.URLDraft, userUUID string) (*entity.URL, error) {
+	if reason := validateURL(draft.Original); reason != "" {
+		return nil, fmt.Errorf("%w: %s", ErrURLInvalid, reason)
+	}
+
+	if err := validateSettings(&draft.URLSettings); err != nil {
+		return nil, err
+	}
+
+	settings, err := draftSettings(draft)
+	if err != nil {
+		return nil, err
+	}
+
 	hash, err := uc.gen.Generate()
 	if err != nil {
 		return nil, err
 	}
 
-	urlObj := &entity.URL{Original: url, Short: hash, UserUUID: userUUID}
+	urlObj := &entity.URL{
+		Original:    draft.Original,
+		Short:       hash,
+		UserUUID:    userUUID,
+		URLSettings: settings,
+	}
 
 	storedURL, err := uc.repo.Store(ctx, urlObj)
 	if errors.Is(err, repo.ErrOriginalURLConflict) {
@@ -87,29 +316,142 @@ func (uc *URLUseCase) SaveURL(ctx context.Context, url string, userUUID string)
 	return storedURL, err
 }
 
-// SaveURLMultiple сохраняет несколько урлов.
-func (uc *URLUseCase) SaveURLMultiple(ctx context.Context, urls []string, userUUID string) ([]*entity.URL, error) {
-	urlObjs := make([]*entity.URL, 0, len(urls))
+// validateURL возвращает причину, по которой урл нельзя сократить, или пустую строку.
+func validateURL(rawURL string) string {
+	if rawURL == "" {
+		return "url is empty"
+	}
+
+	if len(rawURL) > maxURLLength {
+		return fmt.Sprintf("url is longer than %d characters", maxURLLength)
+	}
+
+	parsedURL, err := neturl.ParseRequestURI(rawURL)
+	if err != nil {
+		return "url is malformed"
+	}
+
+	if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
+		return "url scheme must be http or https"
+	}
 
-	if len(urls) == 0 {
-		return urlObjs, nil
+	if parsedURL.Host == "" {
+		return "url host is empty"
 	}
 
-	for _, url := range urls {
+	return ""
+}
+
+// SaveURLMultiple сохраняет несколько урлов, возвращает результат по каждому в том же порядке.
+// Для уже сокращенных урлов возвращается ранее сохраненный хэш, невалидные урлы не сохраняются.
+// В атомарном режиме один невалидный урл отменяет сохранение всей пачки.
+func (uc *URLUseCase) SaveURLMultiple(
+	ctx context.Context,
+	drafts []*entity.URLDraft,
+	userUUID string,
+	mode URLBatchMode,
+) ([]*entity.URLStoreResult, error) {
+	results := make([]*entity.URLStoreResult, len(drafts))
+	urlObjs := make([]*entity.URL, 0, len(drafts))
+	positions := make([]int, 0, len(drafts))
+
+	for i, draft := range drafts {
+		reason := validateURL(draft.Original)
+		if err := validateSettings(&draft.URLSettings); reason == "" && err != nil {
+			reason = err.Error()
+		}
+
+		settings, err := draftSettings(draft)
+		if reason == "" && err != nil {
+			reason = err.Error()
+		}
+
+		if reason != "" {
+			results[i] = &entity.URLStoreResult{Status: entity.URLStoreInvalid, Reason: reason}
+
+			continue
+		}
+
 		hash, err := uc.gen.Generate()
 		if err != nil {
-			return urlObjs, err
+			return nil, err
+		}
+
+		urlObjs = append(urlObjs, &entity.URL{
+			Original:    draft.Original,
+			Short:       hash,
+			UserUUID:    userUUID,
+			URLSettings: settings,
+		})
+		positions = append(positions, i)
+	}
+
+	if mode == URLBatchAtomic && len(urlObjs) != len(drafts) {
+		for _, pos := range positions {
+			results[pos] = &entity.URLStoreResult{Status: entity.URLStoreSkipped}
 		}
 
-		urlObjs = append(urlObjs, &entity.URL{Original: url, Short: hash, UserUUID: userUUID})
+		return results, nil
 	}
 
-	return urlObjs, uc.repo.StoreMultipleURLs(ctx, urlObjs)
+	if len(urlObjs) == 0 {
+		return results, nil
+	}
+
+	storeResults, err := uc.repo.StoreMultipleURLs(ctx, urlObjs)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, pos := range positions {
+		results[pos] = storeResults[i]
+	}
+
+	return results, nil
 }
 
 // ResolveURL определяет полный урл по хэшу.
 func (uc *URLUseCase) ResolveURL(ctx context.Context, hash string) (*entity.URL, error) {
-	return uc.repo.GetURL(ctx, hash)
+	url, err := uc.repo.GetURL(ctx, hash)
+
+	var notFoundErr *repo.URLNotFoundError
+	if errors.As(err, &notFoundErr) {
+		return nil, ErrURLNotFound
+	}
+
+	return url, err
+}
+
+// GetUserURL находит урл пользователя по хэшу. Чужой урл считается ненайденным.
+func (uc *URLUseCase) GetUserURL(ctx context.Context, userUUID string, hash string) (*entity.URL, error) {
+	url, err := uc.repo.GetURL(ctx, hash)
+	if err != nil {
+		var notFoundErr *repo.URLNotFoundError
+		if errors.As(err, &notFoundErr) {
+			return nil, ErrURLNotFound
+		}
+
+		return nil, err
+	}
+
+	if url.UserUUID != userUUID {
+		return nil, ErrURLNotFound
+	}
+
+	return url, nil
+}
+
+// SetURLDisabled выключает или включает ссылку пользователя. Выключенная ссылка сохраняется,
+// но вместо редиректа отдает страницу ошибки.
+func (uc *URLUseCase) SetURLDisabled(ctx context.Context, userUUID string, hash string, disabled bool) error {
+	err := uc.repo.SetURLDisabled(ctx, userUUID, hash, disabled)
+
+	var notFoundErr *repo.URLNotFoundError
+	if errors.As(err, &notFoundErr) {
+		return ErrURLNotFound
+	}
+
+	return err
 }
 
 // GetUserURLS находит все урлы пользователя.
@@ -117,19 +459,264 @@ func (uc *URLUseCase) GetUserURLS(ctx context.Context, userUUID string) ([]*enti
 	return uc.repo.GetUserURLS(ctx, userUUID)
 }
 
+// GetScheduledURLs находит ссылки пользователя, которые еще не начали работать, в порядке активации.
+func (uc *URLUseCase) GetScheduledURLs(ctx context.Context, userUUID string) ([]*entity.URL, error) {
+	return uc.repo.GetUserScheduledURLs(ctx, userUUID, time.Now())
+}
+
+// ExportUserURLs передает в fn все урлы пользователя, включая удаленные, не загружая их разом.
+func (uc *URLUseCase) ExportUserURLs(ctx context.Context, userUUID string, fn func(url *entity.URL) error) error {
+	return uc.repo.IterateUserURLs(ctx, userUUID, fn)
+}
+
+// RedirectCode возвращает код редиректа для ссылки с учетом кода по умолчанию.
+func (uc *URLUseCase) RedirectCode(url *entity.URL) int {
+	if url.RedirectCode != 0 {
+		return url.RedirectCode
+	}
+
+	return uc.defaultRedirectCode
+}
+
+// NotActiveCode возвращает код ответа для ссылки, которая еще не начала работать.
+func (uc *URLUseCase) NotActiveCode() int {
+	return uc.notActiveCode
+}
+
+// RedirectMaxAge возвращает время, на которое редирект по ссылке можно закэшировать.
+// Кэшируются только постоянные редиректы, одинаковые для всех посетителей,
+// ссылки с правилами, вариантами, паролем или предпросмотром всегда проходят через сервис.
+func (uc *URLUseCase) RedirectMaxAge(url *entity.URL) time.Duration {
+	code := uc.RedirectCode(url)
+	if code != http.StatusMovedPermanently && code != http.StatusPermanentRedirect {
+		return 0
+	}
+
+	if len(url.Rules) != 0 || len(url.Variants) != 0 || url.HasPassword() || url.Interstitial {
+		return 0
+	}
+
+	// Кэш не должен пережить срок действия ссылки.
+	if url.ExpiresAt != nil {
+		return max(min(uc.redirectCacheMaxAge, time.Until(*url.ExpiresAt)), 0)
+	}
+
+	return uc.redirectCacheMaxAge
+}
+
+// BuildDestinationURL формирует урл назначения: выбирает его по правилам или вариантам ссылки,
+// переносит в него параметры запроса и добавляет недостающие UTM метки.
+// Собственная строка запроса назначения не перекодируется: новые параметры дописываются в конец,
+// а при переопределении удаляются только заменяемые ключи, поэтому подписанные урлы остаются валидными.
+func (uc *URLUseCase) BuildDestinationURL(url *entity.URL, visit *entity.Visit) *entity.Destination {
+	target := uc.selectDestination(url, visit)
+	passQuery := url.QueryPolicy == entity.URLQueryOverride || url.QueryPolicy == entity.URLQueryKeepExisting
+
+	if len(url.UTM) == 0 && (!passQuery || len(visit.Query) == 0) {
+		return target
+	}
+
+	destination, err := neturl.Parse(target.URL)
+	if err != nil {
+		return target
+	}
+
+	destinationQuery := destination.Query()
+	addedQuery := make(neturl.Values)
+	overriddenKeys := make(map[string]struct{})
+
+	if passQuery {
+		for key, values := range visit.Query {
+			if !destinationQuery.Has(key) {
+				addedQuery[key] = values
+			} else if url.QueryPolicy == entity.URLQueryOverride {
+				addedQuery[key] = values
+				overriddenKeys[key] = struct{}{}
+			}
+		}
+	}
+
+	for key, value := range url.UTM {
+		if !destinationQuery.Has(key) && !addedQuery.Has(key) {
+			addedQuery.Set(key, value)
+		}
+	}
+
+	if len(addedQuery) == 0 {
+		return target
+	}
+
+	rawQuery := removeQueryKeys(destination.RawQuery, overriddenKeys)
+	if rawQuery != "" {
+		rawQuery += "&"
+	}
+
+	destination.RawQuery = rawQuery + addedQuery.Encode()
+	target.URL = destination.String()
+
+	return target
+}
+
+// removeQueryKeys удаляет из строки запроса параметры с ключами keys, не трогая остальные.
+func removeQueryKeys(rawQuery string, keys map[string]struct{}) string {
+	if len(keys) == 0 || rawQuery == "" {
+		return rawQuery
+	}
+
+	kept := make([]string, 0)
+
+	for _, param := range strings.Split(rawQuery, "&") {
+		rawKey, _, _ := strings.Cut(param, "=")
+
+		key, err := neturl.QueryUnescape(rawKey)
+		if err != nil {
+			key = rawKey
+		}
+
+		if _, ok := keys[key]; !ok {
+			kept = append(kept, param)
+		}
+	}
+
+	return strings.Join(kept, "&")
+}
+
 // BuildRedirectURL формирует урл для редиректа.
 func (uc *URLUseCase) BuildRedirectURL(url *entity.URL) string {
 	return fmt.Sprintf("%s/%s", uc.baseRedirectURL, url.Short)
 }
 
-// QueueDelete отправляет задачу на удаление урлов в пул воркеров.
-func (uc *URLUseCase) QueueDelete(deleteItem *entity.URLDeleteItem) error {
-	deleteWork := &URLDeleteWork{
+func (uc *URLUseCase) newDeleteWork(job *entity.URLDeleteJob) *URLDeleteWork {
+	workJob := *job
+
+	return &URLDeleteWork{
 		repo:     uc.repo,
+		jobRepo:  uc.jobRepo,
 		log:      &uc.log,
-		UserUUID: deleteItem.UserUUID,
-		Hashes:   deleteItem.Hashes,
+		job:      &workJob,
+		JobID:    job.ID,
+		UserUUID: job.UserUUID,
+		Hashes:   job.Hashes,
 	}
+}
+
+// QueueDelete создает задачу на удаление урлов и отправляет ее в пул воркеров.
+// Не ждет освобождения очереди: если она заполнена, сразу возвращает ErrURLDeleteQueueFull.
+func (uc *URLUseCase) QueueDelete(
+	ctx context.Context,
+	deleteItem *entity.URLDeleteItem,
+) (*entity.URLDeleteJob, error) {
+	now := time.Now()
+	job := &entity.URLDeleteJob{
+		ID:         uuid.New().String(),
+		UserUUID:   deleteItem.UserUUID,
+		Hashes:     deleteItem.Hashes,
+		Status:     entity.URLDeleteJobQueued,
+		Results:    make([]*entity.URLDeleteResult, 0),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+		Owner:      uc.instanceID,
+		LeaseUntil: now.Add(deleteJobLease),
+	}
+
+	err := uc.jobRepo.Store(ctx, job)
+	if err != nil {
+		return nil, err
+	}
+
+	err = uc.wp.TryQueueWork(uc.newDeleteWork(job))
+	if err != nil {
+		job.Error = err.Error()
+		job.Status = entity.URLDeleteJobFailed
+		job.UpdatedAt = time.Now()
 
-	return uc.wp.QueueWork(deleteWork)
+		if updateErr := uc.jobRepo.Update(ctx, job); updateErr != nil {
+			uc.log.Error().Err(updateErr).Str("jobID", job.ID).Msg("delete job update failed")
+		}
+
+		if errors.Is(err, workerpool.ErrQueueFull) {
+			return nil, ErrURLDeleteQueueFull
+		}
+
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// GetDeleteJob находит задачу удаления пользователя по идентификатору.
+func (uc *URLUseCase) GetDeleteJob(ctx context.Context, userUUID string, jobID string) (*entity.URLDeleteJob, error) {
+	job, err := uc.jobRepo.Get(ctx, jobID)
+	if errors.Is(err, repo.ErrURLDeleteJobNotFound) {
+		return nil, ErrURLDeleteJobNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if job.UserUUID != userUUID {
+		return nil, ErrURLDeleteJobNotFound
+	}
+
+	return job, nil
+}
+
+// ResumeDeleteJobs забирает в пул воркеров незавершенные задачи удаления, аренда которых истекла:
+// их владелец перестал ее продлевать, например, после перезапуска.
+// Возвращает количество возобновленных задач.
+func (uc *URLUseCase) ResumeDeleteJobs(ctx context.Context) (int, error) {
+	jobs, err := uc.jobRepo.ClaimExpired(ctx, uc.instanceID, deleteJobLease, deleteJobResumeBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, job := range jobs {
+		err = uc.wp.QueueWorkContext(ctx, uc.newDeleteWork(job))
+		if err != nil {
+			return i, err
+		}
+	}
+
+	return len(jobs), nil
+}
+
+func (uc *URLUseCase) maintainDeleteJobs(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, deleteJobResumeTimeout)
+	defer cancel()
+
+	if err := uc.jobRepo.ExtendLeases(ctx, uc.instanceID, deleteJobLease); err != nil {
+		uc.log.Error().Err(err).Msg("delete jobs lease extension failed")
+	}
+
+	resumed, err := uc.ResumeDeleteJobs(ctx)
+	if err != nil {
+		uc.log.Error().Err(err).Msg("delete jobs resume failed")
+	} else if resumed > 0 {
+		uc.log.Info().Int("amount", resumed).Msg("delete jobs resumed")
+	}
+
+	deleted, err := uc.jobRepo.DeleteFinished(ctx, time.Now().Add(-deleteJobRetention))
+	if err != nil {
+		uc.log.Error().Err(err).Msg("finished delete jobs cleanup failed")
+	} else if deleted > 0 {
+		uc.log.Info().Int("amount", deleted).Msg("finished delete jobs removed")
+	}
+}
+
+// WatchDeleteJobs периодически продлевает аренду задач удаления этого инстанса, возобновляет задачи
+// с истекшей арендой и удаляет давно завершенные, пока не будет отменен контекст.
+func (uc *URLUseCase) WatchDeleteJobs(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		uc.maintainDeleteJobs(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
 }
@@ -0,0 +1,211 @@
+package usecase_test
+
+import (
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/llravell/go-shortener/internal/entity"
+	"github.com/llravell/go-shortener/internal/mocks"
+	"github.com/llravell/go-shortener/internal/usecase"
+)
+
+func TestURLUseCaseBuildDestinationURL(t *testing.T) {
+	uc := usecase.NewURLUseCase(nil, nil, nil, nil, "http://localhost:8080", zerolog.Nop())
+
+	testCases := []struct {
+		name     string
+		original string
+		settings entity.URLSettings
+		query    url.Values
+		expected string
+	}{
+		{
+			name:     "Drops query by default",
+			query:    url.Values{"utm_source": {"x"}},
+			expected: "https://a.ru/page?ref=1",
+		},
+		{
+			name:     "Keeps existing destination params",
+			settings: entity.URLSettings{QueryPolicy: entity.URLQueryKeepExisting},
+			query:    url.Values{"ref": {"2"}, "utm_source": {"x"}},
+			expected: "https://a.ru/page?ref=1&utm_source=x",
+		},
+		{
+			name:     "Overrides destination params",
+			settings: entity.URLSettings{QueryPolicy: entity.URLQueryOverride},
+			query:    url.Values{"ref": {"2"}},
+			expected: "https://a.ru/page?ref=2",
+		},
+		{
+			name: "Appends utm defaults missing in request",
+			settings: entity.URLSettings{
+				QueryPolicy: entity.URLQueryKeepExisting,
+				UTM:         map[string]string{"utm_source": "newsletter", "utm_medium": "email"},
+			},
+			query:    url.Values{"utm_source": {"x"}},
+			expected: "https://a.ru/page?ref=1&utm_medium=email&utm_source=x",
+		},
+		{
+			name:     "Keeps order and escaping of signed destination query",
+			original: "https://a.ru/file?expires=10&b=2&a=1&sig=a%2Fb%3D",
+			settings: entity.URLSettings{QueryPolicy: entity.URLQueryKeepExisting},
+			query:    url.Values{"utm_source": {"x"}, "sig": {"forged"}},
+			expected: "https://a.ru/file?expires=10&b=2&a=1&sig=a%2Fb%3D&utm_source=x",
+		},
+		{
+			name:     "Overrides only replaced params of destination query",
+			original: "https://a.ru/file?b=2&ref=1&a=%2F",
+			settings: entity.URLSettings{QueryPolicy: entity.URLQueryOverride},
+			query:    url.Values{"ref": {"2"}},
+			expected: "https://a.ru/file?b=2&a=%2F&ref=2",
+		},
+		{
+			name:     "Leaves destination untouched when nothing is added",
+			original: "https://a.ru/file?b=2&a=1",
+			settings: entity.URLSettings{QueryPolicy: entity.URLQueryKeepExisting},
+			query:    url.Values{"a": {"3"}},
+			expected: "https://a.ru/file?b=2&a=1",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			original := tc.original
+			if original == "" {
+				original = "https://a.ru/page?ref=1"
+			}
+
+			destination := uc.BuildDestinationURL(&entity.URL{
+				Original:    original,
+				URLSettings: tc.settings,
+			}, &entity.Visit{Query: tc.query})
+
+			assert.Equal(t, tc.expected, destination.URL)
+		})
+	}
+}
+
+//nolint:funlen
+func TestURLUseCaseBuildDestinationURLWithRules(t *testing.T) {
+	geo := mocks.NewMockGeoLocator(gomock.NewController(t))
+	geo.EXPECT().Country(gomock.Any()).Return("DE", nil).AnyTimes()
+
+	uc := usecase.NewURLUseCase(
+		nil, nil, nil, nil, "http://localhost:8080", zerolog.Nop(),
+		usecase.GeoLocation(geo),
+	)
+
+	shortURL := &entity.URL{
+		Original: "https://a.ru",
+		URLSettings: entity.URLSettings{
+			Rules: []entity.URLRule{
+				{Destination: "https://apps.apple.com/app", Platforms: []string{entity.PlatformIOS}},
+				{Destination: "https://play.google.com/app", Platforms: []string{entity.PlatformAndroid}},
+				{Destination: "https://a.de", Languages: []string{"de"}, Countries: []string{"de"}},
+				{
+					Destination: "https://a.ru/night",
+					TimeWindow:  &entity.URLTimeWindow{From: "22:00", To: "06:00", Timezone: "Europe/Moscow"},
+				},
+			},
+		},
+	}
+
+	day := time.Date(2024, time.December, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name     string
+		visit    *entity.Visit
+		expected string
+	}{
+		{
+			name: "Matches ios platform",
+			visit: &entity.Visit{
+				Time:      day,
+				UserAgent: "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X)",
+			},
+			expected: "https://apps.apple.com/app",
+		},
+		{
+			name: "Matches android platform",
+			visit: &entity.Visit{
+				Time:      day,
+				UserAgent: "Mozilla/5.0 (Linux; Android 14; Pixel 8)",
+			},
+			expected: "https://play.google.com/app",
+		},
+		{
+			name: "Matches language and country",
+			visit: &entity.Visit{
+				Time:           day,
+				IP:             net.ParseIP("93.184.216.34"),
+				AcceptLanguage: "en;q=0.5, de-DE",
+			},
+			expected: "https://a.de",
+		},
+		{
+			name:     "Matches time window across midnight",
+			visit:    &entity.Visit{Time: time.Date(2024, time.December, 1, 21, 30, 0, 0, time.UTC)},
+			expected: "https://a.ru/night",
+		},
+		{
+			name:     "Falls back to original url",
+			visit:    &entity.Visit{Time: day, AcceptLanguage: "de"},
+			expected: "https://a.ru",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, uc.BuildDestinationURL(shortURL, tc.visit).URL)
+		})
+	}
+}
+
+func TestURLUseCaseBuildDestinationURLWithVariants(t *testing.T) {
+	uc := usecase.NewURLUseCase(nil, nil, nil, nil, "http://localhost:8080", zerolog.Nop())
+
+	shortURL := &entity.URL{
+		Original: "https://a.ru",
+		URLSettings: entity.URLSettings{
+			Rules: []entity.URLRule{
+				{Destination: "https://apps.apple.com/app", Platforms: []string{entity.PlatformIOS}},
+			},
+			Variants: []entity.URLVariant{
+				{Name: "a", Destination: "https://a.ru/a", Weight: 1},
+				{Name: "b", Destination: "https://a.ru/b", Weight: 3},
+			},
+		},
+	}
+
+	t.Run("Keeps previously served variant", func(t *testing.T) {
+		destination := uc.BuildDestinationURL(shortURL, &entity.Visit{Variant: "b"})
+
+		assert.Equal(t, &entity.Destination{URL: "https://a.ru/b", Variant: "b"}, destination)
+	})
+
+	t.Run("Picks variants by weight", func(t *testing.T) {
+		served := make(map[string]int)
+
+		for range 1000 {
+			destination := uc.BuildDestinationURL(shortURL, &entity.Visit{Variant: "removed"})
+			served[destination.Variant]++
+		}
+
+		assert.Len(t, served, 2)
+		assert.Greater(t, served["b"], served["a"])
+	})
+
+	t.Run("Rules take precedence over variants", func(t *testing.T) {
+		destination := uc.BuildDestinationURL(shortURL, &entity.Visit{
+			UserAgent: "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X)",
+		})
+
+		assert.Equal(t, &entity.Destination{URL: "https://apps.apple.com/app"}, destination)
+	})
+}
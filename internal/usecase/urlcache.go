@@ -0,0 +1,147 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/llravell/go-shortener/internal/entity"
+	"github.com/llravell/go-shortener/internal/repo"
+	"github.com/llravell/go-shortener/pkg/lrucache"
+)
+
+// URLCache декоратор репозитория, кэширующий поиск урлов по хэшу.
+// Отсутствующие хэши тоже кэшируются, но на время negativeTTL.
+// Одновременные промахи по одному хэшу схлопываются в один запрос к репозиторию.
+// Инвалидация действует только в пределах процесса: если приложение запущено в нескольких
+// экземплярах, изменения, сделанные через другой экземпляр, становятся видны после истечения ttl.
+type URLCache struct {
+	URLRepo
+	cache       *lrucache.Cache[string, *entity.URL]
+	group       singleflight.Group
+	epoch       atomic.Uint64
+	ttl         time.Duration
+	negativeTTL time.Duration
+}
+
+// NewURLCache создает кэширующий декоратор вокруг репозитория.
+func NewURLCache(repo URLRepo, size int, ttl time.Duration, negativeTTL time.Duration) *URLCache {
+	return &URLCache{
+		URLRepo:     repo,
+		cache:       lrucache.New[string, *entity.URL](size),
+		ttl:         ttl,
+		negativeTTL: negativeTTL,
+	}
+}
+
+func copyURL(url *entity.URL) *entity.URL {
+	urlCopy := *url
+
+	return &urlCopy
+}
+
+// GetURL находит урл по хэшу, обращаясь к репозиторию только при промахе кэша.
+func (c *URLCache) GetURL(ctx context.Context, hash string) (*entity.URL, error) {
+	if url, ok := c.cache.Get(hash); ok {
+		if url == nil {
+			return nil, repo.NewURLNotFoundError(hash)
+		}
+
+		return copyURL(url), nil
+	}
+
+	// Загрузка разделяется между всеми ожидающими, поэтому не должна отменяться вместе с первым запросом.
+	v, err, _ := c.group.Do(hash, func() (any, error) {
+		epoch := c.epoch.Load()
+
+		url, err := c.URLRepo.GetURL(context.WithoutCancel(ctx), hash)
+
+		var notFoundErr *repo.URLNotFoundError
+
+		switch {
+		case errors.As(err, &notFoundErr):
+			c.store(epoch, hash, nil, c.negativeTTL)
+		case err == nil:
+			c.store(epoch, hash, url, c.ttl)
+		}
+
+		return url, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	url, _ := v.(*entity.URL)
+
+	return copyURL(url), nil
+}
+
+// store сохраняет результат загрузки, если с ее начала кэш не инвалидировался.
+func (c *URLCache) store(epoch uint64, hash string, url *entity.URL, ttl time.Duration) {
+	if ttl <= 0 || c.epoch.Load() != epoch {
+		return
+	}
+
+	if url != nil {
+		url = copyURL(url)
+	}
+
+	c.cache.Set(hash, url, ttl)
+}
+
+// Invalidate удаляет хэши из кэша.
+func (c *URLCache) Invalidate(hashes ...string) {
+	c.epoch.Add(1)
+
+	for _, hash := range hashes {
+		c.cache.Delete(hash)
+	}
+}
+
+// Store сохраняет урл и сбрасывает закэшированное отсутствие хэша.
+func (c *URLCache) Store(ctx context.Context, url *entity.URL) (*entity.URL, error) {
+	storedURL, err := c.URLRepo.Store(ctx, url)
+
+	c.Invalidate(url.Short)
+
+	return storedURL, err
+}
+
+// StoreMultipleURLs сохраняет несколько урлов и сбрасывает их хэши в кэше.
+func (c *URLCache) StoreMultipleURLs(ctx context.Context, urls []*entity.URL) ([]*entity.URLStoreResult, error) {
+	results, err := c.URLRepo.StoreMultipleURLs(ctx, urls)
+
+	hashes := make([]string, 0, len(urls))
+	for _, url := range urls {
+		hashes = append(hashes, url.Short)
+	}
+
+	c.Invalidate(hashes...)
+
+	return results, err
+}
+
+// SetURLDisabled меняет доступность урла и сбрасывает его хэш в кэше.
+func (c *URLCache) SetURLDisabled(ctx context.Context, userUUID string, hash string, disabled bool) error {
+	err := c.URLRepo.SetURLDisabled(ctx, userUUID, hash, disabled)
+
+	c.Invalidate(hash)
+
+	return err
+}
+
+// DeleteMultipleURLs удаляет урлы и сбрасывает их хэши в кэше.
+func (c *URLCache) DeleteMultipleURLs(
+	ctx context.Context,
+	userUUID string,
+	urlHashes []string,
+) ([]*entity.URLDeleteResult, error) {
+	results, err := c.URLRepo.DeleteMultipleURLs(ctx, userUUID, urlHashes)
+
+	c.Invalidate(urlHashes...)
+
+	return results, err
+}
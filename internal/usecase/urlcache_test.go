@@ -0,0 +1,118 @@
+package usecase_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/llravell/go-shortener/internal/entity"
+	"github.com/llravell/go-shortener/internal/mocks"
+	"github.com/llravell/go-shortener/internal/repo"
+	"github.com/llravell/go-shortener/internal/usecase"
+)
+
+//nolint:funlen
+func TestURLCache(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Reads repo once for cached hash", func(t *testing.T) {
+		urlRepo := mocks.NewMockURLRepo(gomock.NewController(t))
+		cache := usecase.NewURLCache(urlRepo, 10, time.Minute, time.Minute)
+
+		urlRepo.EXPECT().
+			GetURL(gomock.Any(), "a").
+			Return(&entity.URL{Short: "a", Original: "https://a.ru"}, nil).
+			Times(1)
+
+		for range 3 {
+			url, err := cache.GetURL(ctx, "a")
+			require.NoError(t, err)
+			assert.Equal(t, "https://a.ru", url.Original)
+		}
+	})
+
+	t.Run("Caches missing hashes", func(t *testing.T) {
+		urlRepo := mocks.NewMockURLRepo(gomock.NewController(t))
+		cache := usecase.NewURLCache(urlRepo, 10, time.Minute, time.Minute)
+
+		urlRepo.EXPECT().
+			GetURL(gomock.Any(), "missing").
+			Return(nil, repo.NewURLNotFoundError("missing")).
+			Times(1)
+
+		for range 3 {
+			_, err := cache.GetURL(ctx, "missing")
+
+			var notFoundErr *repo.URLNotFoundError
+
+			assert.ErrorAs(t, err, &notFoundErr)
+		}
+	})
+
+	t.Run("Collapses concurrent misses", func(t *testing.T) {
+		urlRepo := mocks.NewMockURLRepo(gomock.NewController(t))
+		cache := usecase.NewURLCache(urlRepo, 10, time.Minute, time.Minute)
+		release := make(chan struct{})
+
+		urlRepo.EXPECT().
+			GetURL(gomock.Any(), "a").
+			DoAndReturn(func(_ context.Context, _ string) (*entity.URL, error) {
+				<-release
+
+				return &entity.URL{Short: "a"}, nil
+			}).
+			Times(1)
+
+		var wg sync.WaitGroup
+
+		for range 10 {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				_, err := cache.GetURL(ctx, "a")
+				assert.NoError(t, err)
+			}()
+		}
+
+		time.Sleep(10 * time.Millisecond)
+		close(release)
+		wg.Wait()
+	})
+
+	t.Run("Invalidates hashes on store and delete", func(t *testing.T) {
+		urlRepo := mocks.NewMockURLRepo(gomock.NewController(t))
+		cache := usecase.NewURLCache(urlRepo, 10, time.Minute, time.Minute)
+
+		gomock.InOrder(
+			urlRepo.EXPECT().GetURL(gomock.Any(), "a").Return(nil, repo.NewURLNotFoundError("a")),
+			urlRepo.EXPECT().Store(gomock.Any(), gomock.Any()).Return(&entity.URL{Short: "a"}, nil),
+			urlRepo.EXPECT().GetURL(gomock.Any(), "a").Return(&entity.URL{Short: "a"}, nil),
+			urlRepo.EXPECT().DeleteMultipleURLs(gomock.Any(), "user", []string{"a"}).Return(nil, nil),
+			urlRepo.EXPECT().GetURL(gomock.Any(), "a").Return(&entity.URL{Short: "a", Deleted: true}, nil),
+		)
+
+		_, err := cache.GetURL(ctx, "a")
+		require.Error(t, err)
+
+		_, err = cache.Store(ctx, &entity.URL{Short: "a"})
+		require.NoError(t, err)
+
+		url, err := cache.GetURL(ctx, "a")
+		require.NoError(t, err)
+		assert.False(t, url.Deleted)
+
+		_, err = cache.DeleteMultipleURLs(ctx, "user", []string{"a"})
+		require.NoError(t, err)
+
+		url, err = cache.GetURL(ctx, "a")
+		require.NoError(t, err)
+		assert.True(t, url.Deleted)
+	})
+}
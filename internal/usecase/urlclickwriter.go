@@ -0,0 +1,117 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/llravell/go-shortener/internal/entity"
+)
+
+const clickStoreTimeout = 5 * time.Second
+
+// URLClickWriterStats метрики записи переходов.
+type URLClickWriterStats struct {
+	Pending int64 `json:"pending"`
+	Stored  int64 `json:"stored"`
+	Failed  int64 `json:"failed"`
+	Dropped int64 `json:"dropped"`
+}
+
+// URLClickWriter декоратор репозитория переходов, который сохраняет переходы в фоне.
+// Очередь ограничена queueSize, при переполнении переходы отбрасываются,
+// чтобы запись аналитики не задерживала редиректы.
+type URLClickWriter struct {
+	ClickRepo
+	log       zerolog.Logger
+	clicks    chan *entity.URLClick
+	stored    atomic.Int64
+	failed    atomic.Int64
+	dropped   atomic.Int64
+	closed    bool
+	closeMu   sync.RWMutex
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// NewURLClickWriter создает писателя и запускает фоновое сохранение переходов.
+func NewURLClickWriter(repo ClickRepo, queueSize int, log zerolog.Logger) *URLClickWriter {
+	w := &URLClickWriter{
+		ClickRepo: repo,
+		log:       log,
+		clicks:    make(chan *entity.URLClick, max(queueSize, 1)),
+	}
+
+	w.wg.Add(1)
+
+	go w.run()
+
+	return w
+}
+
+// StoreClick ставит переход в очередь на сохранение, не дожидаясь записи.
+func (w *URLClickWriter) StoreClick(_ context.Context, click *entity.URLClick) error {
+	w.closeMu.RLock()
+	defer w.closeMu.RUnlock()
+
+	if w.closed {
+		w.dropped.Add(1)
+
+		return nil
+	}
+
+	select {
+	case w.clicks <- click:
+	default:
+		w.dropped.Add(1)
+	}
+
+	return nil
+}
+
+func (w *URLClickWriter) run() {
+	defer w.wg.Done()
+
+	for click := range w.clicks {
+		ctx, cancel := context.WithTimeout(context.Background(), clickStoreTimeout)
+		err := w.ClickRepo.StoreClick(ctx, click)
+
+		cancel()
+
+		if err != nil {
+			w.failed.Add(1)
+			w.log.Err(err).Str("hash", click.Short).Msg("click storing has been failed")
+
+			continue
+		}
+
+		w.stored.Add(1)
+	}
+}
+
+// Stats возвращает число переходов в очереди, сохраненных, несохраненных из-за ошибки и отброшенных.
+func (w *URLClickWriter) Stats() URLClickWriterStats {
+	return URLClickWriterStats{
+		Pending: int64(len(w.clicks)),
+		Stored:  w.stored.Load(),
+		Failed:  w.failed.Load(),
+		Dropped: w.dropped.Load(),
+	}
+}
+
+// Close сохраняет переходы, оставшиеся в очереди, и останавливает фоновую запись.
+func (w *URLClickWriter) Close() error {
+	w.closeOnce.Do(func() {
+		w.closeMu.Lock()
+		w.closed = true
+		close(w.clicks)
+		w.closeMu.Unlock()
+	})
+
+	w.wg.Wait()
+
+	return nil
+}
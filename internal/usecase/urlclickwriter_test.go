@@ -0,0 +1,84 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/llravell/go-shortener/internal/entity"
+	"github.com/llravell/go-shortener/internal/repo"
+	"github.com/llravell/go-shortener/internal/usecase"
+)
+
+type blockingClickRepo struct {
+	*repo.URLClickMemoRepo
+	release chan struct{}
+}
+
+func (r *blockingClickRepo) StoreClick(ctx context.Context, click *entity.URLClick) error {
+	<-r.release
+
+	return r.URLClickMemoRepo.StoreClick(ctx, click)
+}
+
+func TestURLClickWriter(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Stores clicks in background and flushes them on close", func(t *testing.T) {
+		clickRepo := repo.NewURLClickMemoRepo(10)
+		writer := usecase.NewURLClickWriter(clickRepo, 10, zerolog.Nop())
+
+		require.NoError(t, writer.StoreClick(ctx, &entity.URLClick{Short: "a", Variant: "x"}))
+		require.NoError(t, writer.StoreClick(ctx, &entity.URLClick{Short: "a", Variant: "y"}))
+		require.NoError(t, writer.Close())
+
+		stats, err := writer.GetClickStats(ctx, "a")
+		require.NoError(t, err)
+
+		assert.Equal(t, &entity.URLClickStats{
+			Total:    2,
+			Variants: map[string]int64{"x": 1, "y": 1},
+		}, stats)
+		assert.Equal(t, usecase.URLClickWriterStats{Stored: 2}, writer.Stats())
+	})
+
+	t.Run("Drops clicks when queue is full", func(t *testing.T) {
+		clickRepo := &blockingClickRepo{
+			URLClickMemoRepo: repo.NewURLClickMemoRepo(10),
+			release:          make(chan struct{}),
+		}
+		writer := usecase.NewURLClickWriter(clickRepo, 1, zerolog.Nop())
+
+		// первый переход забирает фоновая запись, второй ждет в очереди
+		require.NoError(t, writer.StoreClick(ctx, &entity.URLClick{Short: "a"}))
+		require.Eventually(t, func() bool {
+			return writer.Stats().Pending == 0
+		}, time.Second, time.Millisecond)
+		require.NoError(t, writer.StoreClick(ctx, &entity.URLClick{Short: "a"}))
+
+		start := time.Now()
+
+		require.NoError(t, writer.StoreClick(ctx, &entity.URLClick{Short: "a"}))
+		assert.Less(t, time.Since(start), 100*time.Millisecond)
+		assert.Equal(t, int64(1), writer.Stats().Dropped)
+
+		close(clickRepo.release)
+		require.NoError(t, writer.Close())
+
+		stats, err := writer.GetClickStats(ctx, "a")
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), stats.Total)
+	})
+
+	t.Run("Drops clicks after close", func(t *testing.T) {
+		writer := usecase.NewURLClickWriter(repo.NewURLClickMemoRepo(10), 10, zerolog.Nop())
+		require.NoError(t, writer.Close())
+
+		require.NoError(t, writer.StoreClick(ctx, &entity.URLClick{Short: "a"}))
+		assert.Equal(t, int64(1), writer.Stats().Dropped)
+	})
+}
@@ -0,0 +1,342 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/llravell/go-shortener/internal/entity"
+)
+
+const (
+	deleteBatchFlushTimeout   = time.Second * 30
+	defaultDeleteBatchFlushes = 4
+	deleteBatchRequestsBuffer = 1024
+)
+
+// ErrURLDeleteBatcherClosed ошибка удаления через остановленный батчер.
+var ErrURLDeleteBatcherClosed = errors.New("url delete batcher has been closed")
+
+var errURLDeleteBatchMismatch = errors.New("url delete batch results mismatch")
+
+// URLBatchDeleteRepo репозиторий, умеющий удалять урлы нескольких пользователей одним запросом.
+type URLBatchDeleteRepo interface {
+	URLRepo
+	DeleteURLsBatch(ctx context.Context, items []*entity.URLDeleteItem) ([][]*entity.URLDeleteResult, error)
+}
+
+// URLDeleteBatchStats метрики батчера удаления.
+type URLDeleteBatchStats struct {
+	Flushes         int64         `json:"flushes"`
+	Items           int64         `json:"items"`
+	Hashes          int64         `json:"hashes"`
+	MaxBatchHashes  int64         `json:"max_batch_hashes"`
+	FlushLatency    time.Duration `json:"flush_latency_ns"`
+	MaxFlushLatency time.Duration `json:"max_flush_latency_ns"`
+	WaitLatency     time.Duration `json:"wait_latency_ns"`
+	Pending         int64         `json:"pending"`
+	ActiveFlushes   int64         `json:"active_flushes"`
+}
+
+type urlDeleteBatchMetrics struct {
+	flushes         atomic.Int64
+	items           atomic.Int64
+	hashes          atomic.Int64
+	maxBatchHashes  atomic.Int64
+	flushLatency    atomic.Int64
+	maxFlushLatency atomic.Int64
+	waitLatency     atomic.Int64
+	pending         atomic.Int64
+	activeFlushes   atomic.Int64
+}
+
+func storeMax(v *atomic.Int64, candidate int64) {
+	for {
+		current := v.Load()
+		if candidate <= current || v.CompareAndSwap(current, candidate) {
+			return
+		}
+	}
+}
+
+type urlDeleteRequest struct {
+	queuedAt time.Time
+	item     *entity.URLDeleteItem
+	resultCh chan urlDeleteResponse
+}
+
+type urlDeleteResponse struct {
+	err     error
+	results []*entity.URLDeleteResult
+}
+
+// URLDeleteBatcher декоратор репозитория, который копит запросы на удаление урлов
+// в течение окна window или до maxHashes хэшей и выполняет их одним запросом к репозиторию.
+// Готовые батчи выполняются параллельно, но не больше maxFlushes одновременно,
+// поэтому медленный запрос к базе не останавливает сбор следующего батча.
+type URLDeleteBatcher struct {
+	URLBatchDeleteRepo
+	log        zerolog.Logger
+	requests   chan *urlDeleteRequest
+	flushChan  chan struct{}
+	flushSlots chan struct{}
+	doneChan   chan struct{}
+	metrics    urlDeleteBatchMetrics
+	window     time.Duration
+	maxHashes  int
+	maxFlushes int
+	closed     bool
+	closeMu    sync.RWMutex
+	closeOnce  sync.Once
+	wg         sync.WaitGroup
+	flushWg    sync.WaitGroup
+}
+
+// URLDeleteBatcherOption дополнительная опция батчера.
+type URLDeleteBatcherOption func(b *URLDeleteBatcher)
+
+// MaxDeleteBatchFlushes задает количество батчей, которые могут выполняться одновременно.
+func MaxDeleteBatchFlushes(maxFlushes int) URLDeleteBatcherOption {
+	return func(b *URLDeleteBatcher) {
+		b.maxFlushes = maxFlushes
+	}
+}
+
+// NewURLDeleteBatcher создает батчер и запускает цикл сбора запросов.
+func NewURLDeleteBatcher(
+	repo URLBatchDeleteRepo,
+	window time.Duration,
+	maxHashes int,
+	log zerolog.Logger,
+	opts ...URLDeleteBatcherOption,
+) *URLDeleteBatcher {
+	b := &URLDeleteBatcher{
+		URLBatchDeleteRepo: repo,
+		log:                log,
+		requests:           make(chan *urlDeleteRequest, deleteBatchRequestsBuffer),
+		flushChan:          make(chan struct{}),
+		doneChan:           make(chan struct{}),
+		window:             window,
+		maxHashes:          max(maxHashes, 1),
+		maxFlushes:         defaultDeleteBatchFlushes,
+	}
+
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	b.flushSlots = make(chan struct{}, max(b.maxFlushes, 1))
+
+	b.wg.Add(1)
+
+	go b.run()
+
+	return b
+}
+
+// DeleteMultipleURLs ставит удаление в текущий батч и дожидается его выполнения.
+func (b *URLDeleteBatcher) DeleteMultipleURLs(
+	ctx context.Context,
+	userUUID string,
+	urlHashes []string,
+) ([]*entity.URLDeleteResult, error) {
+	req := &urlDeleteRequest{
+		queuedAt: time.Now(),
+		item:     &entity.URLDeleteItem{UserUUID: userUUID, Hashes: urlHashes},
+		resultCh: make(chan urlDeleteResponse, 1),
+	}
+
+	if err := b.enqueue(ctx, req); err != nil {
+		return nil, err
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case resp := <-req.resultCh:
+		return resp.results, resp.err
+	}
+}
+
+// enqueue передает запрос в цикл сбора. Close дожидается завершения начатых отправок,
+// поэтому запрос, попавший в буфер, не потеряется при остановке.
+func (b *URLDeleteBatcher) enqueue(ctx context.Context, req *urlDeleteRequest) error {
+	b.closeMu.RLock()
+	defer b.closeMu.RUnlock()
+
+	if b.closed {
+		return ErrURLDeleteBatcherClosed
+	}
+
+	b.metrics.pending.Add(1)
+
+	select {
+	case <-ctx.Done():
+		b.metrics.pending.Add(-1)
+
+		return ctx.Err()
+	case b.requests <- req:
+		return nil
+	}
+}
+
+// Flush выполняет накопленный батч, не дожидаясь окончания окна.
+func (b *URLDeleteBatcher) Flush() {
+	select {
+	case b.flushChan <- struct{}{}:
+	case <-b.doneChan:
+	}
+}
+
+func (b *URLDeleteBatcher) run() {
+	defer b.wg.Done()
+	defer b.flushWg.Wait()
+
+	var (
+		batch       []*urlDeleteRequest
+		batchHashes int
+		timer       *time.Timer
+		timerC      <-chan time.Time
+	)
+
+	flush := func() {
+		if timer != nil {
+			timer.Stop()
+			timer, timerC = nil, nil
+		}
+
+		b.dispatch(batch, batchHashes)
+		batch, batchHashes = nil, 0
+	}
+
+	for {
+		select {
+		case <-b.doneChan:
+			// запросы, успевшие попасть в буфер, тоже выполняются
+			for len(b.requests) > 0 {
+				req := <-b.requests
+				batch = append(batch, req)
+				batchHashes += len(req.item.Hashes)
+			}
+
+			if len(batch) > 0 {
+				flush()
+			}
+
+			return
+		case <-timerC:
+			flush()
+		case <-b.flushChan:
+			if len(batch) > 0 {
+				flush()
+			}
+		case req := <-b.requests:
+			batch = append(batch, req)
+			batchHashes += len(req.item.Hashes)
+
+			if batchHashes >= b.maxHashes {
+				flush()
+			} else if timer == nil {
+				timer = time.NewTimer(b.window)
+				timerC = timer.C
+			}
+		}
+	}
+}
+
+// dispatch выполняет батч в отдельной горутине, дожидаясь свободного слота.
+func (b *URLDeleteBatcher) dispatch(batch []*urlDeleteRequest, batchHashes int) {
+	b.flushSlots <- struct{}{}
+	b.flushWg.Add(1)
+
+	go func() {
+		defer func() {
+			<-b.flushSlots
+			b.flushWg.Done()
+		}()
+
+		b.flush(batch, batchHashes)
+	}()
+}
+
+func (b *URLDeleteBatcher) flush(batch []*urlDeleteRequest, batchHashes int) {
+	items := make([]*entity.URLDeleteItem, 0, len(batch))
+	startedAt := time.Now()
+
+	b.metrics.pending.Add(-int64(len(batch)))
+	b.metrics.activeFlushes.Add(1)
+	defer b.metrics.activeFlushes.Add(-1)
+
+	for _, req := range batch {
+		items = append(items, req.item)
+		b.metrics.waitLatency.Add(int64(startedAt.Sub(req.queuedAt)))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), deleteBatchFlushTimeout)
+	results, err := b.DeleteURLsBatch(ctx, items)
+
+	cancel()
+
+	if err == nil && len(results) != len(batch) {
+		err = errURLDeleteBatchMismatch
+	}
+
+	latency := time.Since(startedAt)
+
+	b.metrics.flushes.Add(1)
+	b.metrics.items.Add(int64(len(batch)))
+	b.metrics.hashes.Add(int64(batchHashes))
+	b.metrics.flushLatency.Add(int64(latency))
+	storeMax(&b.metrics.maxBatchHashes, int64(batchHashes))
+	storeMax(&b.metrics.maxFlushLatency, int64(latency))
+
+	b.log.Debug().
+		Err(err).
+		Int("items", len(batch)).
+		Int("hashes", batchHashes).
+		Dur("latency", latency).
+		Msg("delete batch flushed")
+
+	for i, req := range batch {
+		resp := urlDeleteResponse{err: err}
+		if err == nil {
+			resp.results = results[i]
+		}
+
+		req.resultCh <- resp
+	}
+}
+
+// Stats возвращает накопленные метрики: количество сбросов, размер батчей,
+// суммарные задержки ожидания и выполнения, а также число ожидающих запросов и выполняемых батчей.
+func (b *URLDeleteBatcher) Stats() URLDeleteBatchStats {
+	return URLDeleteBatchStats{
+		Flushes:         b.metrics.flushes.Load(),
+		Items:           b.metrics.items.Load(),
+		Hashes:          b.metrics.hashes.Load(),
+		MaxBatchHashes:  b.metrics.maxBatchHashes.Load(),
+		FlushLatency:    time.Duration(b.metrics.flushLatency.Load()),
+		MaxFlushLatency: time.Duration(b.metrics.maxFlushLatency.Load()),
+		WaitLatency:     time.Duration(b.metrics.waitLatency.Load()),
+		Pending:         b.metrics.pending.Load(),
+		ActiveFlushes:   b.metrics.activeFlushes.Load(),
+	}
+}
+
+// Close сбрасывает накопленный батч и останавливает цикл сбора запросов.
+func (b *URLDeleteBatcher) Close() error {
+	b.closeOnce.Do(func() {
+		b.closeMu.Lock()
+		b.closed = true
+		close(b.doneChan)
+		b.closeMu.Unlock()
+	})
+
+	b.wg.Wait()
+
+	return nil
+}
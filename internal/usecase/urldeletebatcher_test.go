@@ -0,0 +1,145 @@
+package usecase_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/llravell/go-shortener/internal/entity"
+	"github.com/llravell/go-shortener/internal/repo"
+	"github.com/llravell/go-shortener/internal/usecase"
+)
+
+type blockingBatchDeleteRepo struct {
+	*repo.URLMemoRepo
+	release chan struct{}
+}
+
+func (r *blockingBatchDeleteRepo) DeleteURLsBatch(
+	ctx context.Context,
+	items []*entity.URLDeleteItem,
+) ([][]*entity.URLDeleteResult, error) {
+	<-r.release
+
+	return r.URLMemoRepo.DeleteURLsBatch(ctx, items)
+}
+
+//nolint:funlen
+func TestURLDeleteBatcher(t *testing.T) {
+	prepareRepo := func() *repo.URLMemoRepo {
+		memoRepo := repo.NewURLMemoRepo()
+		memoRepo.Init([]*entity.URL{
+			{Short: "a", Original: "https://a.ru", UserUUID: "first"},
+			{Short: "b", Original: "https://b.ru", UserUUID: "second"},
+		})
+
+		return memoRepo
+	}
+
+	t.Run("Coalesces deletes of several users into one flush", func(t *testing.T) {
+		batcher := usecase.NewURLDeleteBatcher(prepareRepo(), time.Hour, 100, zerolog.Nop())
+		defer batcher.Close()
+
+		var (
+			wg            sync.WaitGroup
+			firstResults  []*entity.URLDeleteResult
+			secondResults []*entity.URLDeleteResult
+		)
+
+		wg.Add(2)
+
+		go func() {
+			defer wg.Done()
+
+			var err error
+
+			firstResults, err = batcher.DeleteMultipleURLs(context.Background(), "first", []string{"a", "b"})
+			assert.NoError(t, err)
+		}()
+
+		go func() {
+			defer wg.Done()
+
+			var err error
+
+			secondResults, err = batcher.DeleteMultipleURLs(context.Background(), "second", []string{"b"})
+			assert.NoError(t, err)
+		}()
+
+		require.Eventually(t, func() bool {
+			return batcher.Stats().Pending == 2
+		}, time.Second, time.Millisecond)
+
+		batcher.Flush()
+		wg.Wait()
+
+		assert.Equal(t, []*entity.URLDeleteResult{
+			{Hash: "a", Status: entity.URLDeleteDone},
+			{Hash: "b", Status: entity.URLDeleteNotOwned},
+		}, firstResults)
+		assert.Equal(t, []*entity.URLDeleteResult{
+			{Hash: "b", Status: entity.URLDeleteDone},
+		}, secondResults)
+
+		stats := batcher.Stats()
+		assert.Equal(t, int64(1), stats.Flushes)
+		assert.Equal(t, int64(2), stats.Items)
+		assert.Equal(t, int64(3), stats.MaxBatchHashes)
+	})
+
+	t.Run("Flushes as soon as batch is full", func(t *testing.T) {
+		batcher := usecase.NewURLDeleteBatcher(prepareRepo(), time.Hour, 1, zerolog.Nop())
+		defer batcher.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		results, err := batcher.DeleteMultipleURLs(ctx, "first", []string{"a"})
+		require.NoError(t, err)
+		assert.Equal(t, []*entity.URLDeleteResult{{Hash: "a", Status: entity.URLDeleteDone}}, results)
+	})
+
+	t.Run("Runs several batches at once", func(t *testing.T) {
+		blockingRepo := &blockingBatchDeleteRepo{
+			URLMemoRepo: prepareRepo(),
+			release:     make(chan struct{}),
+		}
+		batcher := usecase.NewURLDeleteBatcher(blockingRepo, time.Hour, 1, zerolog.Nop(), usecase.MaxDeleteBatchFlushes(2))
+		defer batcher.Close()
+
+		var wg sync.WaitGroup
+
+		for _, userUUID := range []string{"first", "second"} {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				_, err := batcher.DeleteMultipleURLs(context.Background(), userUUID, []string{"a"})
+				assert.NoError(t, err)
+			}()
+		}
+
+		require.Eventually(t, func() bool {
+			return batcher.Stats().ActiveFlushes == 2
+		}, time.Second, time.Millisecond)
+
+		close(blockingRepo.release)
+		wg.Wait()
+
+		assert.Equal(t, int64(2), batcher.Stats().Flushes)
+	})
+
+	t.Run("Rejects deletes after close", func(t *testing.T) {
+		batcher := usecase.NewURLDeleteBatcher(prepareRepo(), time.Millisecond, 1, zerolog.Nop())
+		require.NoError(t, batcher.Close())
+
+		_, err := batcher.DeleteMultipleURLs(context.Background(), "first", []string{"a"})
+		assert.ErrorIs(t, err, usecase.ErrURLDeleteBatcherClosed)
+	})
+}
@@ -0,0 +1,190 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/llravell/go-shortener/internal/entity"
+	"github.com/llravell/go-shortener/internal/mocks"
+	"github.com/llravell/go-shortener/internal/repo"
+	"github.com/llravell/go-shortener/internal/usecase"
+	"github.com/llravell/go-shortener/pkg/workerpool"
+)
+
+func storeDeleteJob(t *testing.T, jobRepo usecase.URLDeleteJobRepo, job *entity.URLDeleteJob) {
+	t.Helper()
+
+	job.UserUUID = "user"
+	job.Hashes = []string{"a"}
+	job.CreatedAt = time.Now()
+	job.UpdatedAt = time.Now()
+
+	require.NoError(t, jobRepo.Store(context.Background(), job))
+}
+
+//nolint:funlen
+func TestURLUseCaseResumeDeleteJobs(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Does not resume jobs queued by alive instance", func(t *testing.T) {
+		jobRepo := repo.NewURLDeleteJobMemoRepo()
+		wp := mocks.NewMockURLDeleteWorkerPool(gomock.NewController(t))
+		owner := usecase.NewURLUseCase(nil, jobRepo, wp, nil, "", zerolog.Nop(), usecase.InstanceID("a"))
+		peer := usecase.NewURLUseCase(nil, jobRepo, wp, nil, "", zerolog.Nop(), usecase.InstanceID("b"))
+
+		wp.EXPECT().TryQueueWork(gomock.Any()).Return(nil)
+
+		_, err := owner.QueueDelete(ctx, &entity.URLDeleteItem{UserUUID: "user", Hashes: []string{"a"}})
+		require.NoError(t, err)
+
+		for _, uc := range []*usecase.URLUseCase{owner, peer} {
+			resumed, err := uc.ResumeDeleteJobs(ctx)
+			require.NoError(t, err)
+			assert.Zero(t, resumed)
+		}
+	})
+
+	t.Run("Claims job with expired lease only once", func(t *testing.T) {
+		jobRepo := repo.NewURLDeleteJobMemoRepo()
+		wp := mocks.NewMockURLDeleteWorkerPool(gomock.NewController(t))
+		first := usecase.NewURLUseCase(nil, jobRepo, wp, nil, "", zerolog.Nop(), usecase.InstanceID("a"))
+		second := usecase.NewURLUseCase(nil, jobRepo, wp, nil, "", zerolog.Nop(), usecase.InstanceID("b"))
+
+		storeDeleteJob(t, jobRepo, &entity.URLDeleteJob{
+			ID:         "job",
+			Status:     entity.URLDeleteJobRunning,
+			Owner:      "dead",
+			LeaseUntil: time.Now().Add(-time.Second),
+		})
+
+		wp.EXPECT().QueueWorkContext(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+		resumed, err := first.ResumeDeleteJobs(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, resumed)
+
+		resumed, err = second.ResumeDeleteJobs(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, resumed)
+
+		job, err := jobRepo.Get(ctx, "job")
+		require.NoError(t, err)
+		assert.Equal(t, "a", job.Owner)
+		assert.True(t, job.LeaseUntil.After(time.Now()))
+	})
+
+	t.Run("Watcher extends own leases and removes old finished jobs", func(t *testing.T) {
+		jobRepo := repo.NewURLDeleteJobMemoRepo()
+		wp := mocks.NewMockURLDeleteWorkerPool(gomock.NewController(t))
+		uc := usecase.NewURLUseCase(nil, jobRepo, wp, nil, "", zerolog.Nop(), usecase.InstanceID("a"))
+
+		storeDeleteJob(t, jobRepo, &entity.URLDeleteJob{
+			ID:         "own",
+			Status:     entity.URLDeleteJobQueued,
+			Owner:      "a",
+			LeaseUntil: time.Now().Add(-time.Second),
+		})
+		storeDeleteJob(t, jobRepo, &entity.URLDeleteJob{ID: "finished", Status: entity.URLDeleteJobSucceeded})
+
+		finishedJob, err := jobRepo.Get(ctx, "finished")
+		require.NoError(t, err)
+
+		finishedJob.UpdatedAt = time.Now().Add(-48 * time.Hour)
+		require.NoError(t, jobRepo.Update(ctx, finishedJob))
+
+		watchCtx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		uc.WatchDeleteJobs(watchCtx, time.Hour)
+
+		job, err := jobRepo.Get(ctx, "own")
+		require.NoError(t, err)
+		assert.True(t, job.LeaseUntil.After(time.Now()))
+
+		_, err = jobRepo.Get(ctx, "finished")
+		require.ErrorIs(t, err, repo.ErrURLDeleteJobNotFound)
+	})
+	t.Run("Keeps finished jobs for whole retention period", func(t *testing.T) {
+		jobRepo := repo.NewURLDeleteJobMemoRepo()
+		wp := mocks.NewMockURLDeleteWorkerPool(gomock.NewController(t))
+		uc := usecase.NewURLUseCase(nil, jobRepo, wp, nil, "", zerolog.Nop(), usecase.InstanceID("a"))
+
+		storeDeleteJob(t, jobRepo, &entity.URLDeleteJob{ID: "finished", Status: entity.URLDeleteJobSucceeded})
+
+		finishedJob, err := jobRepo.Get(ctx, "finished")
+		require.NoError(t, err)
+
+		finishedJob.UpdatedAt = time.Now().Add(-2 * time.Hour)
+		require.NoError(t, jobRepo.Update(ctx, finishedJob))
+
+		wp.EXPECT().TryQueueWork(gomock.Any()).Return(nil)
+
+		_, err = uc.QueueDelete(ctx, &entity.URLDeleteItem{UserUUID: "user", Hashes: []string{"a"}})
+		require.NoError(t, err)
+
+		watchCtx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		uc.WatchDeleteJobs(watchCtx, time.Hour)
+
+		job, err := uc.GetDeleteJob(ctx, "user", "finished")
+		require.NoError(t, err)
+		assert.Equal(t, entity.URLDeleteJobSucceeded, job.Status)
+	})
+
+	t.Run("Keeps job interrupted by shutdown resumable", func(t *testing.T) {
+		jobRepo := repo.NewURLDeleteJobMemoRepo()
+		urlRepo := mocks.NewMockURLRepo(gomock.NewController(t))
+		started := make(chan struct{})
+
+		urlRepo.EXPECT().
+			DeleteMultipleURLs(gomock.Any(), "user", []string{"a"}).
+			DoAndReturn(func(ctx context.Context, _ string, _ []string) ([]*entity.URLDeleteResult, error) {
+				close(started)
+				<-ctx.Done()
+
+				return nil, ctx.Err()
+			})
+
+		wp := workerpool.New(1,
+			workerpool.Retry[*usecase.URLDeleteWork](workerpool.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Hour}),
+			workerpool.DeadLetter(func(work *usecase.URLDeleteWork, err error) {
+				work.Fail(context.Background(), err)
+			}),
+		)
+		uc := usecase.NewURLUseCase(urlRepo, jobRepo, wp, nil, "", zerolog.Nop(), usecase.InstanceID("a"))
+
+		wp.ProcessQueue()
+
+		job, err := uc.QueueDelete(ctx, &entity.URLDeleteItem{UserUUID: "user", Hashes: []string{"a"}})
+		require.NoError(t, err)
+
+		<-started
+		wp.Close()
+		wp.Wait()
+
+		stored, err := jobRepo.Get(ctx, job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, entity.URLDeleteJobQueued, stored.Status)
+		assert.Equal(t, "a", stored.Owner)
+
+		// после перезапуска аренда остановленного инстанса истекает
+		stored.LeaseUntil = time.Now().Add(-time.Second)
+		require.NoError(t, jobRepo.Store(ctx, stored))
+
+		restartedWP := mocks.NewMockURLDeleteWorkerPool(gomock.NewController(t))
+		restarted := usecase.NewURLUseCase(nil, jobRepo, restartedWP, nil, "", zerolog.Nop(), usecase.InstanceID("b"))
+
+		restartedWP.EXPECT().QueueWorkContext(gomock.Any(), gomock.Any()).Return(nil)
+
+		resumed, err := restarted.ResumeDeleteJobs(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, resumed)
+	})
+}
@@ -0,0 +1,81 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/llravell/go-shortener/internal/entity"
+)
+
+const (
+	maxURLPasswordLength          = 72
+	defaultPasswordAttemptsLimit  = 5
+	defaultPasswordAttemptsPeriod = 15 * time.Minute
+)
+
+// ErrURLPasswordInvalid ошибка проверки пароля ссылки.
+var ErrURLPasswordInvalid = errors.New("invalid url password")
+
+// ErrURLPasswordTooLong ошибка слишком длинного пароля ссылки.
+var ErrURLPasswordTooLong = fmt.Errorf(
+	"%w: password must be at most %d bytes",
+	ErrURLSettingsInvalid,
+	maxURLPasswordLength,
+)
+
+// URLPasswordAttemptsError ошибка превышения числа попыток ввода пароля.
+type URLPasswordAttemptsError struct {
+	RetryAfter time.Duration
+}
+
+// Error реализация интерфейса ошибки.
+func (err *URLPasswordAttemptsError) Error() string {
+	return fmt.Sprintf("too many password attempts, retry after %s", err.RetryAfter)
+}
+
+// draftSettings возвращает настройки ссылки с хэшем пароля из черновика.
+// Хэш, переданный в настройках напрямую, отбрасывается.
+func draftSettings(draft *entity.URLDraft) (entity.URLSettings, error) {
+	settings := draft.URLSettings
+	settings.PasswordHash = ""
+
+	if draft.Password == "" {
+		return settings, nil
+	}
+
+	if len(draft.Password) > maxURLPasswordLength {
+		return settings, ErrURLPasswordTooLong
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(draft.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return settings, err
+	}
+
+	settings.PasswordHash = string(hash)
+
+	return settings, nil
+}
+
+// VerifyPassword проверяет пароль ссылки. Попытки ограничиваются для каждой пары ссылки и клиента,
+// успешная проверка сбрасывает счетчик.
+func (uc *URLUseCase) VerifyPassword(url *entity.URL, clientKey string, password string) error {
+	key := url.Short + "|" + clientKey
+
+	allowed, retryAfter := uc.passwordLimiter.Allow(key)
+	if !allowed {
+		return &URLPasswordAttemptsError{RetryAfter: retryAfter}
+	}
+
+	err := bcrypt.CompareHashAndPassword([]byte(url.PasswordHash), []byte(password))
+	if err != nil {
+		return ErrURLPasswordInvalid
+	}
+
+	uc.passwordLimiter.Reset(key)
+
+	return nil
+}
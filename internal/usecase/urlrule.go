@@ -0,0 +1,271 @@
+package usecase
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/llravell/go-shortener/internal/entity"
+)
+
+const (
+	maxURLRules      = 20
+	timeWindowLayout = "15:04"
+)
+
+// ErrURLRulesInvalid ошибка недопустимых правил редиректа.
+var ErrURLRulesInvalid = fmt.Errorf("%w: invalid redirect rules", ErrURLSettingsInvalid)
+
+func validateRules(rules []entity.URLRule) error {
+	if len(rules) > maxURLRules {
+		return fmt.Errorf("%w: no more than %d rules allowed", ErrURLRulesInvalid, maxURLRules)
+	}
+
+	for i := range rules {
+		if err := validateRule(&rules[i]); err != nil {
+			return fmt.Errorf("%w: rule %d: %s", ErrURLRulesInvalid, i, err.Error())
+		}
+	}
+
+	return nil
+}
+
+func validateRule(rule *entity.URLRule) error {
+	if reason := validateURL(rule.Destination); reason != "" {
+		return fmt.Errorf("destination %s", strings.TrimPrefix(reason, "url "))
+	}
+
+	for _, platform := range rule.Platforms {
+		if !slices.Contains(entity.Platforms, platform) {
+			return fmt.Errorf("unknown platform %q", platform)
+		}
+	}
+
+	for _, language := range rule.Languages {
+		if language == "" {
+			return fmt.Errorf("language is empty")
+		}
+	}
+
+	for _, country := range rule.Countries {
+		if len(country) != 2 {
+			return fmt.Errorf("country %q is not an ISO 3166-1 alpha-2 code", country)
+		}
+	}
+
+	if rule.TimeWindow == nil {
+		return nil
+	}
+
+	if _, err := time.Parse(timeWindowLayout, rule.TimeWindow.From); err != nil {
+		return fmt.Errorf("time window start %q is not in HH:MM format", rule.TimeWindow.From)
+	}
+
+	if _, err := time.Parse(timeWindowLayout, rule.TimeWindow.To); err != nil {
+		return fmt.Errorf("time window end %q is not in HH:MM format", rule.TimeWindow.To)
+	}
+
+	if err := rule.TimeWindow.ResolveLocation(); err != nil {
+		return fmt.Errorf("unknown timezone %q", rule.TimeWindow.Timezone)
+	}
+
+	return nil
+}
+
+// detectPlatform определяет платформу посетителя по User-Agent.
+// Порядок проверок важен: User-Agent Android содержит Linux, а iOS содержит Mac OS X.
+func detectPlatform(userAgent string) string {
+	switch {
+	case strings.Contains(userAgent, "iPhone"),
+		strings.Contains(userAgent, "iPad"),
+		strings.Contains(userAgent, "iPod"):
+		return entity.PlatformIOS
+	case strings.Contains(userAgent, "Android"):
+		return entity.PlatformAndroid
+	case strings.Contains(userAgent, "Windows"):
+		return entity.PlatformWindows
+	case strings.Contains(userAgent, "Macintosh"), strings.Contains(userAgent, "Mac OS X"):
+		return entity.PlatformMacOS
+	case strings.Contains(userAgent, "Linux"):
+		return entity.PlatformLinux
+	default:
+		return entity.PlatformOther
+	}
+}
+
+// parseAcceptLanguage возвращает языки из заголовка Accept-Language в порядке предпочтения.
+func parseAcceptLanguage(header string) []string {
+	type language struct {
+		tag     string
+		quality float64
+	}
+
+	languages := make([]language, 0)
+
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		quality := 1.0
+
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+
+			quality = parsed
+		}
+
+		if quality > 0 {
+			languages = append(languages, language{tag: strings.ToLower(tag), quality: quality})
+		}
+	}
+
+	slices.SortStableFunc(languages, func(a, b language) int {
+		return cmp.Compare(b.quality, a.quality)
+	})
+
+	tags := make([]string, 0, len(languages))
+	for _, l := range languages {
+		tags = append(tags, l.tag)
+	}
+
+	return tags
+}
+
+// matchLanguage проверяет, что язык посетителя подходит под язык правила:
+// правило "en" подходит для "en-US", правило "en-US" только для "en-US".
+func matchLanguage(ruleLanguage string, visitLanguages []string) bool {
+	ruleLanguage = strings.ToLower(ruleLanguage)
+
+	for _, tag := range visitLanguages {
+		if tag == ruleLanguage || strings.HasPrefix(tag, ruleLanguage+"-") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// inTimeWindow проверяет попадание в интервал. Часовой пояс берется из интервала, где он загружен
+// при проверке или чтении настроек, и ищется заново, только если интервал собран в обход них.
+func inTimeWindow(window *entity.URLTimeWindow, now time.Time) bool {
+	location := window.Location()
+	if location == nil {
+		loaded, err := time.LoadLocation(window.Timezone)
+		if err != nil {
+			return false
+		}
+
+		location = loaded
+	}
+
+	from, errFrom := time.Parse(timeWindowLayout, window.From)
+	to, errTo := time.Parse(timeWindowLayout, window.To)
+
+	if errFrom != nil || errTo != nil {
+		return false
+	}
+
+	now = now.In(location)
+	minute := now.Hour()*60 + now.Minute()
+	fromMinute := from.Hour()*60 + from.Minute()
+	toMinute := to.Hour()*60 + to.Minute()
+
+	if fromMinute <= toMinute {
+		return minute >= fromMinute && minute < toMinute
+	}
+
+	return minute >= fromMinute || minute < toMinute
+}
+
+// visitMatcher проверяет правила для одного перехода, вычисляя признаки посетителя по мере надобности.
+type visitMatcher struct {
+	visit     *entity.Visit
+	geo       GeoLocator
+	platform  string
+	languages []string
+	country   string
+	located   bool
+}
+
+func (m *visitMatcher) getPlatform() string {
+	if m.platform == "" {
+		m.platform = detectPlatform(m.visit.UserAgent)
+	}
+
+	return m.platform
+}
+
+func (m *visitMatcher) getLanguages() []string {
+	if m.languages == nil {
+		m.languages = parseAcceptLanguage(m.visit.AcceptLanguage)
+	}
+
+	return m.languages
+}
+
+func (m *visitMatcher) getCountry() string {
+	if m.located || m.geo == nil || m.visit.IP == nil {
+		return m.country
+	}
+
+	m.located = true
+
+	country, err := m.geo.Country(m.visit.IP)
+	if err == nil {
+		m.country = country
+	}
+
+	return m.country
+}
+
+func (m *visitMatcher) match(rule *entity.URLRule) bool {
+	if len(rule.Platforms) != 0 && !slices.Contains(rule.Platforms, m.getPlatform()) {
+		return false
+	}
+
+	if len(rule.Languages) != 0 && !slices.ContainsFunc(rule.Languages, func(language string) bool {
+		return matchLanguage(language, m.getLanguages())
+	}) {
+		return false
+	}
+
+	if len(rule.Countries) != 0 && !slices.ContainsFunc(rule.Countries, func(country string) bool {
+		return strings.EqualFold(country, m.getCountry())
+	}) {
+		return false
+	}
+
+	if rule.TimeWindow != nil && !inTimeWindow(rule.TimeWindow, m.visit.Time) {
+		return false
+	}
+
+	return true
+}
+
+// selectDestination возвращает урл назначения первого подходящего правила,
+// иначе один из вариантов A/B теста или исходный урл.
+func (uc *URLUseCase) selectDestination(url *entity.URL, visit *entity.Visit) *entity.Destination {
+	if len(url.Rules) != 0 {
+		matcher := &visitMatcher{visit: visit, geo: uc.geo}
+
+		for i := range url.Rules {
+			if matcher.match(&url.Rules[i]) {
+				return &entity.Destination{URL: url.Rules[i].Destination}
+			}
+		}
+	}
+
+	if variant := pickVariant(url.Variants, visit.Variant); variant != nil {
+		return &entity.Destination{URL: variant.Destination, Variant: variant.Name}
+	}
+
+	return &entity.Destination{URL: url.Original}
+}
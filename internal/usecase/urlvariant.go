@@ -0,0 +1,109 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"regexp"
+	"time"
+
+	"github.com/llravell/go-shortener/internal/entity"
+)
+
+const maxURLVariants = 10
+
+// ErrURLVariantsInvalid ошибка недопустимых вариантов A/B теста.
+var ErrURLVariantsInvalid = fmt.Errorf("%w: invalid variants", ErrURLSettingsInvalid)
+
+// ErrURLNotFound ошибка поиска урла пользователя.
+var ErrURLNotFound = errors.New("url not found")
+
+// Имя варианта попадает в куки, поэтому допускаются только безопасные символы.
+var variantNameRe = regexp.MustCompile(`^[A-Za-z0-9_-]{1,32}$`)
+
+func validateVariants(variants []entity.URLVariant) error {
+	if len(variants) > maxURLVariants {
+		return fmt.Errorf("%w: no more than %d variants allowed", ErrURLVariantsInvalid, maxURLVariants)
+	}
+
+	names := make(map[string]struct{}, len(variants))
+
+	for i, variant := range variants {
+		if !variantNameRe.MatchString(variant.Name) {
+			return fmt.Errorf("%w: variant %d: name must match %s", ErrURLVariantsInvalid, i, variantNameRe)
+		}
+
+		if _, ok := names[variant.Name]; ok {
+			return fmt.Errorf("%w: variant %d: duplicate name %q", ErrURLVariantsInvalid, i, variant.Name)
+		}
+
+		names[variant.Name] = struct{}{}
+
+		if variant.Weight <= 0 {
+			return fmt.Errorf("%w: variant %d: weight must be positive", ErrURLVariantsInvalid, i)
+		}
+
+		if reason := validateURL(variant.Destination); reason != "" {
+			return fmt.Errorf("%w: variant %d: destination %s", ErrURLVariantsInvalid, i, reason)
+		}
+	}
+
+	return nil
+}
+
+// pickVariant возвращает вариант, показанный посетителю ранее, если он еще существует,
+// иначе выбирает вариант случайно пропорционально весам.
+func pickVariant(variants []entity.URLVariant, assigned string) *entity.URLVariant {
+	total := 0
+
+	for i := range variants {
+		if assigned != "" && variants[i].Name == assigned {
+			return &variants[i]
+		}
+
+		total += variants[i].Weight
+	}
+
+	if total <= 0 {
+		return nil
+	}
+
+	n := rand.IntN(total) //nolint:gosec // для распределения трафика криптостойкость не нужна
+
+	for i := range variants {
+		n -= variants[i].Weight
+		if n < 0 {
+			return &variants[i]
+		}
+	}
+
+	return nil
+}
+
+// RecordClick сохраняет переход по ссылке с показанным вариантом, если подключена аналитика.
+// Учитываются только ссылки с вариантами A/B теста, остальные редиректы не пишут в хранилище.
+func (uc *URLUseCase) RecordClick(ctx context.Context, url *entity.URL, destination *entity.Destination) error {
+	if uc.clicks == nil || len(url.Variants) == 0 {
+		return nil
+	}
+
+	return uc.clicks.StoreClick(ctx, &entity.URLClick{
+		Time:    time.Now(),
+		Short:   url.Short,
+		Variant: destination.Variant,
+	})
+}
+
+// GetClickStats возвращает сводку переходов по ссылке пользователя в разрезе вариантов.
+func (uc *URLUseCase) GetClickStats(ctx context.Context, userUUID string, hash string) (*entity.URLClickStats, error) {
+	if _, err := uc.GetUserURL(ctx, userUUID, hash); err != nil {
+		return nil, err
+	}
+
+	if uc.clicks == nil {
+		return &entity.URLClickStats{Variants: make(map[string]int64)}, nil
+	}
+
+	return uc.clicks.GetClickStats(ctx, hash)
+}
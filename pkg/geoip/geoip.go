@@ -0,0 +1,45 @@
+// Пакет geoip определяет страну по ip адресу с помощью локальной базы в формате MaxMind DB.
+package geoip
+
+import (
+	"net"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// DB база соответствия ip адресов и стран.
+type DB struct {
+	reader *maxminddb.Reader
+}
+
+type countryRecord struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+}
+
+// Open открывает файл базы, например GeoLite2-Country.mmdb.
+func Open(path string) (*DB, error) {
+	reader, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DB{reader: reader}, nil
+}
+
+// Country возвращает ISO код страны для ip адреса или пустую строку, если адреса нет в базе.
+func (db *DB) Country(ip net.IP) (string, error) {
+	var record countryRecord
+
+	if err := db.reader.Lookup(ip, &record); err != nil {
+		return "", err
+	}
+
+	return record.Country.ISOCode, nil
+}
+
+// Close закрывает файл базы.
+func (db *DB) Close() error {
+	return db.reader.Close()
+}
@@ -0,0 +1,184 @@
+package geoip
+
+import (
+	"encoding/binary"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testNetwork struct {
+	cidr    string
+	country string
+}
+
+// Пустая ветка дерева и ссылка на данные кодируются отрицательными номерами узлов.
+const emptyRecord = -1
+
+func dataRecord(idx int) int { return -2 - idx }
+
+func encodeString(s string) []byte {
+	return append([]byte{0x40 | byte(len(s))}, s...)
+}
+
+func encodeMap(size int) []byte {
+	return []byte{0xE0 | byte(size)}
+}
+
+func encodeUint16(v uint16) []byte {
+	return binary.BigEndian.AppendUint16([]byte{0xA0 | 2}, v)
+}
+
+func encodeUint32(v uint32) []byte {
+	return binary.BigEndian.AppendUint32([]byte{0xC0 | 4}, v)
+}
+
+// writeTestDatabase собирает минимальную IPv4 базу в формате MaxMind DB с размером записи 24 бита.
+func writeTestDatabase(t *testing.T, networks []testNetwork) string {
+	t.Helper()
+
+	nodes := [][2]int{{emptyRecord, emptyRecord}}
+	offsets := make([]int, 0, len(networks))
+
+	var data []byte
+
+	for idx, network := range networks {
+		_, ipNet, err := net.ParseCIDR(network.cidr)
+		require.NoError(t, err)
+
+		ip := ipNet.IP.To4()
+		ones, _ := ipNet.Mask.Size()
+		node := 0
+
+		for i := range ones {
+			bit := int(ip[i/8]>>(7-i%8)) & 1
+
+			if i == ones-1 {
+				nodes[node][bit] = dataRecord(idx)
+
+				break
+			}
+
+			if nodes[node][bit] < 0 {
+				nodes = append(nodes, [2]int{emptyRecord, emptyRecord})
+				nodes[node][bit] = len(nodes) - 1
+			}
+
+			node = nodes[node][bit]
+		}
+
+		offsets = append(offsets, len(data))
+		data = append(data, encodeMap(1)...)
+		data = append(data, encodeString("country")...)
+		data = append(data, encodeMap(1)...)
+		data = append(data, encodeString("iso_code")...)
+		data = append(data, encodeString(network.country)...)
+	}
+
+	nodeCount := len(nodes)
+	resolve := func(record int) uint32 {
+		switch {
+		case record == emptyRecord:
+			return uint32(nodeCount)
+		case record < emptyRecord:
+			return uint32(nodeCount + 16 + offsets[-2-record])
+		default:
+			return uint32(record)
+		}
+	}
+
+	var db []byte
+
+	for _, node := range nodes {
+		for _, record := range node {
+			value := resolve(record)
+			db = append(db, byte(value>>16), byte(value>>8), byte(value))
+		}
+	}
+
+	db = append(db, make([]byte, 16)...)
+	db = append(db, data...)
+	db = append(db, "\xAB\xCD\xEFMaxMind.com"...)
+	db = append(db, encodeMap(5)...)
+	db = append(db, encodeString("node_count")...)
+	db = append(db, encodeUint32(uint32(nodeCount))...)
+	db = append(db, encodeString("record_size")...)
+	db = append(db, encodeUint16(24)...)
+	db = append(db, encodeString("ip_version")...)
+	db = append(db, encodeUint16(4)...)
+	db = append(db, encodeString("database_type")...)
+	db = append(db, encodeString("Test-Country")...)
+	db = append(db, encodeString("binary_format_major_version")...)
+	db = append(db, encodeUint16(2)...)
+
+	path := filepath.Join(t.TempDir(), "test.mmdb")
+	require.NoError(t, os.WriteFile(path, db, 0o600))
+
+	return path
+}
+
+func TestDBCountry(t *testing.T) {
+	db, err := Open(writeTestDatabase(t, []testNetwork{
+		{cidr: "81.2.69.0/24", country: "GB"},
+		{cidr: "89.160.20.0/22", country: "SE"},
+	}))
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	testCases := []struct {
+		name            string
+		ip              net.IP
+		expectedCountry string
+		wantErr         bool
+	}{
+		{
+			name:            "Finds country of ip",
+			ip:              net.ParseIP("81.2.69.160"),
+			expectedCountry: "GB",
+		},
+		{
+			name:            "Finds country of ip at network edge",
+			ip:              net.ParseIP("89.160.23.255"),
+			expectedCountry: "SE",
+		},
+		{
+			name:            "Finds country of ipv4 mapped to ipv6",
+			ip:              net.ParseIP("::ffff:81.2.69.1"),
+			expectedCountry: "GB",
+		},
+		{
+			name:            "Returns empty code for unknown ip",
+			ip:              net.ParseIP("89.160.24.1"),
+			expectedCountry: "",
+		},
+		{
+			name:    "Fails on ipv6 in ipv4 database",
+			ip:      net.ParseIP("2001:db8::1"),
+			wantErr: true,
+		},
+		{
+			name:    "Fails on missing ip",
+			ip:      nil,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			country, err := db.Country(tc.ip)
+			if tc.wantErr {
+				assert.Error(t, err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedCountry, country)
+		})
+	}
+}
@@ -0,0 +1,111 @@
+// Пакет lrucache представляет потокобезопасный LRU кэш ограниченного размера
+// с временем жизни записей.
+package lrucache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+type entry[K comparable, V any] struct {
+	expiresAt time.Time
+	key       K
+	value     V
+}
+
+// Cache LRU кэш. При переполнении вытесняется запись, к которой дольше всего не обращались.
+type Cache[K comparable, V any] struct {
+	items map[K]*list.Element
+	order *list.List
+	now   func() time.Time
+	size  int
+	mu    sync.Mutex
+}
+
+// New создает кэш, вмещающий не больше size записей.
+func New[K comparable, V any](size int) *Cache[K, V] {
+	return &Cache[K, V]{
+		items: make(map[K]*list.Element, size),
+		order: list.New(),
+		now:   time.Now,
+		size:  max(size, 1),
+	}
+}
+
+// Get возвращает значение по ключу, если оно есть в кэше и еще не истекло.
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var zero V
+
+	elem, ok := c.items[key]
+	if !ok {
+		return zero, false
+	}
+
+	e, _ := elem.Value.(*entry[K, V])
+
+	if !e.expiresAt.After(c.now()) {
+		c.removeElement(elem)
+
+		return zero, false
+	}
+
+	c.order.MoveToFront(elem)
+
+	return e.value, true
+}
+
+// Set сохраняет значение на время ttl.
+func (c *Cache[K, V]) Set(key K, value V, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := c.now().Add(ttl)
+
+	if elem, ok := c.items[key]; ok {
+		e, _ := elem.Value.(*entry[K, V])
+		e.value = value
+		e.expiresAt = expiresAt
+		c.order.MoveToFront(elem)
+
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&entry[K, V]{
+		key:       key,
+		value:     value,
+		expiresAt: expiresAt,
+	})
+
+	for c.order.Len() > c.size {
+		c.removeElement(c.order.Back())
+	}
+}
+
+// Delete удаляет значение по ключу.
+func (c *Cache[K, V]) Delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.items[key]; ok {
+		c.removeElement(elem)
+	}
+}
+
+// Len возвращает количество записей в кэше, включая еще не удаленные истекшие.
+func (c *Cache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
+
+func (c *Cache[K, V]) removeElement(elem *list.Element) {
+	e, _ := elem.Value.(*entry[K, V])
+
+	c.order.Remove(elem)
+	delete(c.items, e.key)
+}
@@ -0,0 +1,57 @@
+package lrucache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache(t *testing.T) {
+	t.Run("Evicts least recently used entry", func(t *testing.T) {
+		cache := New[string, int](2)
+
+		cache.Set("a", 1, time.Minute)
+		cache.Set("b", 2, time.Minute)
+
+		_, ok := cache.Get("a")
+		assert.True(t, ok)
+
+		cache.Set("c", 3, time.Minute)
+
+		_, ok = cache.Get("b")
+		assert.False(t, ok)
+
+		value, ok := cache.Get("a")
+		assert.True(t, ok)
+		assert.Equal(t, 1, value)
+		assert.Equal(t, 2, cache.Len())
+	})
+
+	t.Run("Expires entries after ttl", func(t *testing.T) {
+		now := time.Now()
+		cache := New[string, int](2)
+		cache.now = func() time.Time { return now }
+
+		cache.Set("a", 1, time.Second)
+
+		_, ok := cache.Get("a")
+		assert.True(t, ok)
+
+		now = now.Add(time.Second)
+
+		_, ok = cache.Get("a")
+		assert.False(t, ok)
+		assert.Equal(t, 0, cache.Len())
+	})
+
+	t.Run("Deletes entry", func(t *testing.T) {
+		cache := New[string, int](2)
+
+		cache.Set("a", 1, time.Minute)
+		cache.Delete("a")
+
+		_, ok := cache.Get("a")
+		assert.False(t, ok)
+	})
+}
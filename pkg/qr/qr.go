@@ -0,0 +1,116 @@
+// Пакет qr рисует QR коды в PNG и SVG с произвольным размером и отступом.
+package qr
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strings"
+
+	"github.com/skip2/go-qrcode"
+)
+
+// ErrLevelUnknown ошибка неизвестного уровня коррекции ошибок.
+var ErrLevelUnknown = errors.New("unknown error correction level")
+
+var levels = map[string]qrcode.RecoveryLevel{
+	"L": qrcode.Low,
+	"M": qrcode.Medium,
+	"Q": qrcode.High,
+	"H": qrcode.Highest,
+}
+
+// Code QR код без рамки, отступ добавляется при отрисовке.
+type Code struct {
+	bitmap [][]bool
+}
+
+// New кодирует содержимое с уровнем коррекции ошибок L, M, Q или H.
+func New(content string, level string) (*Code, error) {
+	recoveryLevel, ok := levels[strings.ToUpper(level)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrLevelUnknown, level)
+	}
+
+	code, err := qrcode.New(content, recoveryLevel)
+	if err != nil {
+		return nil, err
+	}
+
+	code.DisableBorder = true
+
+	return &Code{bitmap: code.Bitmap()}, nil
+}
+
+// layout вычисляет размер модуля и итоговый размер изображения. Размер модуля целый,
+// поэтому остаток размера уходит в отступ, а слишком маленький размер увеличивается.
+func (c *Code) layout(size, margin int) (int, int) {
+	modules := len(c.bitmap) + 2*margin
+	scale := max(size/modules, 1)
+
+	return scale, max(size, modules*scale)
+}
+
+// PNG рисует код размером size пикселей с отступом margin модулей.
+func (c *Code) PNG(w io.Writer, size, margin int) error {
+	scale, imgSize := c.layout(size, margin)
+	offset := (imgSize - len(c.bitmap)*scale) / 2
+
+	img := image.NewPaletted(
+		image.Rect(0, 0, imgSize, imgSize),
+		color.Palette{color.White, color.Black},
+	)
+
+	for y, row := range c.bitmap {
+		for x, dark := range row {
+			if !dark {
+				continue
+			}
+
+			for dy := range scale {
+				start := img.PixOffset(offset+x*scale, offset+y*scale+dy)
+				for dx := range scale {
+					img.Pix[start+dx] = 1
+				}
+			}
+		}
+	}
+
+	encoder := png.Encoder{CompressionLevel: png.BestCompression}
+
+	return encoder.Encode(w, img)
+}
+
+// SVG рисует код размером size пикселей с отступом margin модулей.
+// Темные модули одной строки объединяются в один отрезок пути.
+func (c *Code) SVG(w io.Writer, size, margin int) error {
+	modules := len(c.bitmap) + 2*margin
+
+	var path strings.Builder
+
+	for y, row := range c.bitmap {
+		for x := 0; x < len(row); x++ {
+			if !row[x] {
+				continue
+			}
+
+			start := x
+			for x < len(row) && row[x] {
+				x++
+			}
+
+			fmt.Fprintf(&path, "M%d %dh%dv1h-%dz", start+margin, y+margin, x-start, x-start)
+		}
+	}
+
+	_, err := fmt.Fprintf(w,
+		`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`+
+			`<rect width="100%%" height="100%%" fill="#fff"/><path fill="#000" d="%s"/></svg>`+"\n",
+		size, size, modules, modules, path.String(),
+	)
+
+	return err
+}
@@ -0,0 +1,69 @@
+package qr
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCode(t *testing.T) {
+	code, err := New("http://localhost:8080/abc", "m")
+	require.NoError(t, err)
+
+	modules := len(code.bitmap)
+
+	t.Run("Draws png of requested size", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		require.NoError(t, code.PNG(&buf, 256, 4))
+
+		img, err := png.Decode(&buf)
+		require.NoError(t, err)
+
+		assert.Equal(t, 256, img.Bounds().Dx())
+		assert.Equal(t, 256, img.Bounds().Dy())
+
+		scale := 256 / (modules + 8)
+		offset := (256 - modules*scale) / 2
+
+		r, _, _, _ := img.At(0, 0).RGBA()
+		assert.Equal(t, uint32(0xffff), r)
+
+		// Верхний левый угол кода всегда темный из-за метки позиционирования.
+		r, _, _, _ = img.At(offset, offset).RGBA()
+		assert.Equal(t, uint32(0), r)
+	})
+
+	t.Run("Enlarges too small png", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		require.NoError(t, code.PNG(&buf, 10, 0))
+
+		img, err := png.Decode(&buf)
+		require.NoError(t, err)
+
+		assert.Equal(t, modules, img.Bounds().Dx())
+	})
+
+	t.Run("Draws svg with margin in view box", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		require.NoError(t, code.SVG(&buf, 300, 2))
+
+		svg := buf.String()
+
+		assert.True(t, strings.HasPrefix(svg, `<svg xmlns="http://www.w3.org/2000/svg" width="300" height="300"`))
+		assert.Contains(t, svg, `viewBox="0 0 29 29"`)
+		assert.Contains(t, svg, `d="M2 2h7v1h-7z`)
+	})
+
+	t.Run("Rejects unknown level", func(t *testing.T) {
+		_, err := New("a", "X")
+
+		assert.ErrorIs(t, err, ErrLevelUnknown)
+	})
+}
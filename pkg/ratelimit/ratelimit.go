@@ -0,0 +1,79 @@
+// Пакет ratelimit представляет потокобезопасный ограничитель числа попыток
+// по ключу в фиксированном окне времени.
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+type window struct {
+	resetAt  time.Time
+	attempts int
+}
+
+// Limiter ограничитель попыток. Окно ключа начинается с первой попытки,
+// истекшие окна удаляются не чаще одного раза за период.
+type Limiter struct {
+	windows   map[string]*window
+	now       func() time.Time
+	lastSweep time.Time
+	period    time.Duration
+	limit     int
+	mu        sync.Mutex
+}
+
+// New создает ограничитель, допускающий не больше limit попыток по ключу за period.
+func New(limit int, period time.Duration) *Limiter {
+	return &Limiter{
+		windows: make(map[string]*window),
+		now:     time.Now,
+		period:  period,
+		limit:   max(limit, 1),
+	}
+}
+
+// Allow учитывает попытку по ключу. Если лимит исчерпан, возвращает false
+// и время, через которое попытки снова станут доступны.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	w, ok := l.windows[key]
+	if !ok || !w.resetAt.After(now) {
+		w = &window{resetAt: now.Add(l.period)}
+		l.windows[key] = w
+	}
+
+	if w.attempts >= l.limit {
+		return false, w.resetAt.Sub(now)
+	}
+
+	w.attempts++
+
+	return true, 0
+}
+
+// Reset сбрасывает попытки по ключу, например, после успешной проверки.
+func (l *Limiter) Reset(key string) {
+	l.mu.Lock()
+	delete(l.windows, key)
+	l.mu.Unlock()
+}
+
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.period {
+		return
+	}
+
+	l.lastSweep = now
+
+	for key, w := range l.windows {
+		if !w.resetAt.After(now) {
+			delete(l.windows, key)
+		}
+	}
+}
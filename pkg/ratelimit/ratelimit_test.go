@@ -0,0 +1,54 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimiter(t *testing.T) {
+	now := time.Now()
+
+	limiter := New(2, time.Minute)
+	limiter.now = func() time.Time { return now }
+
+	t.Run("Rejects attempts over limit until window ends", func(t *testing.T) {
+		allowed, _ := limiter.Allow("a")
+		assert.True(t, allowed)
+
+		allowed, _ = limiter.Allow("a")
+		assert.True(t, allowed)
+
+		now = now.Add(20 * time.Second)
+
+		allowed, retryAfter := limiter.Allow("a")
+		assert.False(t, allowed)
+		assert.Equal(t, 40*time.Second, retryAfter)
+
+		allowed, _ = limiter.Allow("b")
+		assert.True(t, allowed)
+
+		now = now.Add(40 * time.Second)
+
+		allowed, _ = limiter.Allow("a")
+		assert.True(t, allowed)
+	})
+
+	t.Run("Reset clears attempts", func(t *testing.T) {
+		limiter.Allow("c")
+		limiter.Allow("c")
+		limiter.Reset("c")
+
+		allowed, _ := limiter.Allow("c")
+		assert.True(t, allowed)
+	})
+
+	t.Run("Sweeps expired windows", func(t *testing.T) {
+		now = now.Add(2 * time.Minute)
+
+		limiter.Allow("d")
+
+		assert.Len(t, limiter.windows, 1)
+	})
+}
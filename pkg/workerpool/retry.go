@@ -0,0 +1,57 @@
+package workerpool
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// DefaultMaxRetryDelay - верхняя граница задержки, если RetryPolicy.MaxDelay не задан.
+const DefaultMaxRetryDelay = time.Minute
+
+// RetryPolicy описывает повторное выполнение задач, завершившихся ошибкой.
+// Задержка между попытками растет экспоненциально от BaseDelay, но не превышает MaxDelay
+// (если MaxDelay не задан, используется DefaultMaxRetryDelay).
+// Jitter в диапазоне [0, 1] определяет долю задержки, которая выбирается случайно.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	Jitter      float64
+}
+
+// Backoff возвращает задержку перед попыткой с номером attempt (нумерация с 1).
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	if attempt <= 1 || p.BaseDelay <= 0 {
+		return 0
+	}
+
+	maxDelay := p.MaxDelay
+	if maxDelay <= 0 {
+		maxDelay = max(DefaultMaxRetryDelay, p.BaseDelay)
+	}
+
+	delay := min(p.BaseDelay, maxDelay)
+
+	// удвоение прекращается до достижения maxDelay, поэтому переполнения не происходит
+	for i := 2; i < attempt && delay < maxDelay; i++ {
+		if delay > maxDelay/2 {
+			delay = maxDelay
+
+			break
+		}
+
+		delay *= 2
+	}
+
+	jitter := min(max(p.Jitter, 0), 1)
+	if jitter > 0 {
+		//nolint:gosec // криптостойкость для разброса задержек не требуется
+		delay -= time.Duration(rand.Float64() * jitter * float64(delay))
+	}
+
+	return delay
+}
+
+func (p RetryPolicy) maxAttempts() int {
+	return max(p.MaxAttempts, 1)
+}
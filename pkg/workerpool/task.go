@@ -0,0 +1,146 @@
+package workerpool
+
+import (
+	"context"
+	"runtime/debug"
+	"sync"
+)
+
+// Task задача, возвращающая типизированный результат.
+type Task[R any] func(ctx context.Context) (R, error)
+
+// Result результат выполнения задачи.
+type Result[R any] struct {
+	Value R
+	Err   error
+}
+
+// Future позволяет дождаться результата задачи, поставленной в пул через Submit.
+type Future[R any] struct {
+	done   chan struct{}
+	result Result[R]
+	once   sync.Once
+}
+
+func newFuture[R any]() *Future[R] {
+	return &Future[R]{done: make(chan struct{})}
+}
+
+func (f *Future[R]) complete(value R, err error) {
+	f.once.Do(func() {
+		f.result = Result[R]{Value: value, Err: err}
+		close(f.done)
+	})
+}
+
+// Done возвращает канал, который закрывается после завершения задачи.
+func (f *Future[R]) Done() <-chan struct{} {
+	return f.done
+}
+
+// Get дожидается завершения задачи или отмены контекста.
+func (f *Future[R]) Get(ctx context.Context) (R, error) {
+	select {
+	case <-ctx.Done():
+		var zero R
+
+		return zero, ctx.Err()
+	case <-f.done:
+		return f.result.Value, f.result.Err
+	}
+}
+
+// taskWork адаптирует Task к интерфейсу Work.
+// Ошибка задачи передается в Future, поэтому повторные попытки пула к задачам не применяются.
+type taskWork[R any] struct {
+	ctx    context.Context //nolint:containedctx // контекст того, кто поставил задачу
+	task   Task[R]
+	future *Future[R]
+}
+
+func (w *taskWork[R]) Do(poolCtx context.Context) error {
+	var zero R
+
+	if err := w.ctx.Err(); err != nil {
+		w.future.complete(zero, err)
+
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(w.ctx)
+	defer cancel()
+
+	stop := context.AfterFunc(poolCtx, cancel)
+	defer stop()
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			w.future.complete(zero, &PanicError{Value: recovered, Stack: debug.Stack()})
+
+			panic(recovered)
+		}
+	}()
+
+	value, err := w.task(ctx)
+	w.future.complete(value, err)
+
+	return nil
+}
+
+// Submit ставит задачу в пул и возвращает Future с ее результатом.
+// Контекст ограничивает ожидание места в очереди и передается в задачу.
+func Submit[R any](ctx context.Context, wp *WorkerPool[Work], task Task[R]) (*Future[R], error) {
+	future := newFuture[R]()
+
+	err := wp.QueueWorkContext(ctx, &taskWork[R]{
+		ctx:    ctx,
+		task:   task,
+		future: future,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return future, nil
+}
+
+// RunAll выполняет задачи, одновременно запуская не больше concurrency из них,
+// и возвращает результаты в порядке переданных задач.
+func RunAll[R any](ctx context.Context, concurrency int, tasks []Task[R]) []Result[R] {
+	results := make([]Result[R], len(tasks))
+
+	if len(tasks) == 0 {
+		return results
+	}
+
+	wp := New(min(max(concurrency, 1), len(tasks)), QueueSize[Work](len(tasks)))
+	wp.ProcessQueue()
+
+	defer func() {
+		wp.Close()
+		wp.Wait()
+	}()
+
+	futures := make([]*Future[R], len(tasks))
+
+	for i, task := range tasks {
+		future, err := Submit(ctx, wp, task)
+		if err != nil {
+			results[i].Err = err
+
+			continue
+		}
+
+		futures[i] = future
+	}
+
+	for i, future := range futures {
+		if future == nil {
+			continue
+		}
+
+		results[i].Value, results[i].Err = future.Get(ctx)
+	}
+
+	return results
+}
@@ -0,0 +1,109 @@
+package workerpool
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubmit(t *testing.T) {
+	wp := New[Work](2)
+	wp.ProcessQueue()
+
+	defer func() {
+		wp.Close()
+		wp.Wait()
+	}()
+
+	t.Run("Returns typed result", func(t *testing.T) {
+		future, err := Submit(context.Background(), wp, func(_ context.Context) (int, error) {
+			return 42, nil
+		})
+		require.NoError(t, err)
+
+		value, err := future.Get(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 42, value)
+	})
+
+	t.Run("Returns task error", func(t *testing.T) {
+		future, err := Submit(context.Background(), wp, func(_ context.Context) (string, error) {
+			return "", errWorkFailed
+		})
+		require.NoError(t, err)
+
+		_, err = future.Get(context.Background())
+		assert.ErrorIs(t, err, errWorkFailed)
+	})
+
+	t.Run("Returns panic as error", func(t *testing.T) {
+		future, err := Submit(context.Background(), wp, func(_ context.Context) (string, error) {
+			panic("boom")
+		})
+		require.NoError(t, err)
+
+		_, err = future.Get(context.Background())
+
+		var panicErr *PanicError
+
+		require.ErrorAs(t, err, &panicErr)
+		assert.Equal(t, "boom", panicErr.Value)
+	})
+}
+
+func TestRunAll(t *testing.T) {
+	const (
+		tasksAmount = 20
+		concurrency = 3
+	)
+
+	var (
+		running    atomic.Int32
+		maxRunning atomic.Int32
+	)
+
+	tasks := make([]Task[string], 0, tasksAmount)
+
+	for i := range tasksAmount {
+		tasks = append(tasks, func(_ context.Context) (string, error) {
+			current := running.Add(1)
+			defer running.Add(-1)
+
+			for {
+				observed := maxRunning.Load()
+				if current <= observed || maxRunning.CompareAndSwap(observed, current) {
+					break
+				}
+			}
+
+			time.Sleep(time.Millisecond)
+
+			if i%5 == 0 {
+				return "", errWorkFailed
+			}
+
+			return strconv.Itoa(i), nil
+		})
+	}
+
+	results := RunAll(context.Background(), concurrency, tasks)
+
+	require.Len(t, results, tasksAmount)
+	assert.LessOrEqual(t, maxRunning.Load(), int32(concurrency))
+
+	for i, result := range results {
+		if i%5 == 0 {
+			assert.ErrorIs(t, result.Err, errWorkFailed)
+
+			continue
+		}
+
+		require.NoError(t, result.Err)
+		assert.Equal(t, strconv.Itoa(i), result.Value)
+	}
+}
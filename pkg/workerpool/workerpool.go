@@ -4,67 +4,387 @@ package workerpool
 import (
 	"context"
 	"errors"
+	"fmt"
+	"runtime/debug"
 	"sync"
 	"sync/atomic"
+	"time"
 )
 
 const (
 	_defaultWorksChanSize = 64
+	_defaultIdleTimeout   = 30 * time.Second
 )
 
 // ErrHasBeenAlreadyClosed ошибка повторного закрытия WorkerPool.
 var ErrHasBeenAlreadyClosed = errors.New("worker pool has been already closed")
 
+// ErrQueueFull ошибка постановки задачи в заполненную очередь.
+var ErrQueueFull = errors.New("worker pool queue is full")
+
 // Work определяет интерфейс выполняемых задач.
+// Ошибка, возвращенная из Do, приводит к повторной попытке согласно RetryPolicy.
 type Work interface {
-	Do(ctx context.Context)
+	Do(ctx context.Context) error
+}
+
+// PanicHandler получает значение паники, возникшей при выполнении задачи, и стек вызовов.
+type PanicHandler[W Work] func(work W, recovered any, stack []byte)
+
+// PanicError ошибка задачи, выполнение которой завершилось паникой.
+// Такие задачи не повторяются и сразу передаются в DeadLetterSink.
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+// Error реализация интерфейса ошибки.
+func (err *PanicError) Error() string {
+	return fmt.Sprintf("work panicked: %v", err.Value)
+}
+
+// Stats счетчики WorkerPool'а.
+type Stats struct {
+	Workers  int   `json:"workers"`
+	QueueLen int   `json:"queue_len"`
+	Panics   int64 `json:"panics"`
+	Timeouts int64 `json:"timeouts"`
+}
+
+// DeadLetterSink принимает задачи, которые не удалось выполнить за все попытки.
+// Задачи, прерванные закрытием пула, сюда не попадают: они не провалены, а не доделаны.
+type DeadLetterSink[W Work] func(work W, err error)
+
+// Option дополнительная опция WorkerPool'а.
+type Option[W Work] func(wp *WorkerPool[W])
+
+// Retry устанавливает политику повторного выполнения задач.
+func Retry[W Work](policy RetryPolicy) Option[W] {
+	return func(wp *WorkerPool[W]) {
+		wp.retryPolicy = policy
+	}
+}
+
+// DeadLetter устанавливает получателя задач, исчерпавших все попытки.
+func DeadLetter[W Work](sink DeadLetterSink[W]) Option[W] {
+	return func(wp *WorkerPool[W]) {
+		wp.deadLetterSink = sink
+	}
+}
+
+// QueueSize устанавливает размер очереди задач.
+func QueueSize[W Work](size int) Option[W] {
+	return func(wp *WorkerPool[W]) {
+		wp.queueSize = size
+	}
+}
+
+// MaxWorkers разрешает пулу добавлять воркеров сверх базового количества, пока в очереди копятся задачи.
+func MaxWorkers[W Work](amount int) Option[W] {
+	return func(wp *WorkerPool[W]) {
+		wp.maxWorkers = amount
+	}
+}
+
+// IdleTimeout устанавливает время простоя, после которого лишний воркер останавливается.
+func IdleTimeout[W Work](timeout time.Duration) Option[W] {
+	return func(wp *WorkerPool[W]) {
+		wp.idleTimeout = timeout
+	}
+}
+
+// OnPanic устанавливает обработчик паник, возникших при выполнении задач.
+func OnPanic[W Work](handler PanicHandler[W]) Option[W] {
+	return func(wp *WorkerPool[W]) {
+		wp.panicHandler = handler
+	}
+}
+
+// WorkTimeout ограничивает время одной попытки выполнения задачи.
+func WorkTimeout[W Work](timeout time.Duration) Option[W] {
+	return func(wp *WorkerPool[W]) {
+		wp.workTimeout = timeout
+	}
 }
 
 // WorkerPool структура, предоставляющая интерфейс для распараллеливания задач.
 type WorkerPool[W Work] struct {
-	workersAmount int
-	worksChan     chan W
-	doneChan      chan struct{}
-	closed        atomic.Bool
-	processOnce   sync.Once
-	wg            sync.WaitGroup
+	deadLetterSink DeadLetterSink[W]
+	panicHandler   PanicHandler[W]
+	ctx            context.Context //nolint:containedctx // контекст воркеров, добавляемых при масштабировании
+	worksChan      chan W
+	doneChan       chan struct{}
+	retryPolicy    RetryPolicy
+	workersAmount  int
+	maxWorkers     int
+	idleTimeout    time.Duration
+	workTimeout    time.Duration
+	queueSize      int
+	workers        atomic.Int64
+	panics         atomic.Int64
+	timeouts       atomic.Int64
+	closed         atomic.Bool
+	processOnce    sync.Once
+	scaleMu        sync.Mutex
+	wg             sync.WaitGroup
 }
 
 // New создает инстанс WorkerPool'а, дает возможность задать количество воркеров.
-func New[W Work](workersAmount int) *WorkerPool[W] {
-	return &WorkerPool[W]{
+// Это количество остается минимальным, если опцией MaxWorkers разрешено масштабирование.
+func New[W Work](workersAmount int, opts ...Option[W]) *WorkerPool[W] {
+	wp := &WorkerPool[W]{
 		workersAmount: workersAmount,
-		worksChan:     make(chan W, _defaultWorksChanSize),
+		idleTimeout:   _defaultIdleTimeout,
+		queueSize:     _defaultWorksChanSize,
 		doneChan:      make(chan struct{}),
 	}
+
+	for _, opt := range opts {
+		opt(wp)
+	}
+
+	wp.maxWorkers = max(wp.maxWorkers, wp.workersAmount)
+	wp.worksChan = make(chan W, max(wp.queueSize, 0))
+
+	return wp
 }
 
 // QueueWork добавляет задачу в очередь на обработку.
+// Блокируется, пока в очереди не освободится место или пул не будет закрыт.
 func (wp *WorkerPool[W]) QueueWork(work W) error {
+	return wp.QueueWorkContext(context.Background(), work)
+}
+
+// QueueWorkContext добавляет задачу в очередь на обработку, ожидая свободного места не дольше,
+// чем живет контекст. Если место так и не освободилось, возвращает ErrQueueFull вместе с ошибкой контекста.
+func (wp *WorkerPool[W]) QueueWorkContext(ctx context.Context, work W) error {
 	if wp.closed.Load() {
 		return ErrHasBeenAlreadyClosed
 	}
 
-	wp.worksChan <- work
+	select {
+	case <-wp.doneChan:
+		return ErrHasBeenAlreadyClosed
+	case wp.worksChan <- work:
+		wp.scaleUp()
 
-	return nil
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("%w: %w", ErrQueueFull, ctx.Err())
+	}
+}
+
+// TryQueueWork добавляет задачу в очередь без ожидания.
+// Возвращает ErrQueueFull, если очередь заполнена.
+func (wp *WorkerPool[W]) TryQueueWork(work W) error {
+	if wp.closed.Load() {
+		return ErrHasBeenAlreadyClosed
+	}
+
+	select {
+	case <-wp.doneChan:
+		return ErrHasBeenAlreadyClosed
+	case wp.worksChan <- work:
+		wp.scaleUp()
+
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// Workers возвращает текущее количество воркеров.
+func (wp *WorkerPool[W]) Workers() int {
+	return int(wp.workers.Load())
+}
+
+// QueueLen возвращает количество задач, ожидающих обработки.
+func (wp *WorkerPool[W]) QueueLen() int {
+	return len(wp.worksChan)
+}
+
+// Stats возвращает текущее состояние пула и счетчики паник и таймаутов задач.
+func (wp *WorkerPool[W]) Stats() Stats {
+	return Stats{
+		Workers:  wp.Workers(),
+		QueueLen: wp.QueueLen(),
+		Panics:   wp.panics.Load(),
+		Timeouts: wp.timeouts.Load(),
+	}
+}
+
+// scaleUp добавляет воркера, если задачи копятся в очереди, а верхняя граница еще не достигнута.
+func (wp *WorkerPool[W]) scaleUp() {
+	if wp.maxWorkers <= wp.workersAmount || len(wp.worksChan) == 0 {
+		return
+	}
+
+	wp.scaleMu.Lock()
+	defer wp.scaleMu.Unlock()
+
+	if wp.ctx == nil || wp.closed.Load() || wp.Workers() >= wp.maxWorkers {
+		return
+	}
+
+	wp.startWorker()
+}
+
+// retire останавливает воркера, если их больше базового количества.
+func (wp *WorkerPool[W]) retire() bool {
+	for {
+		current := wp.workers.Load()
+		if current <= int64(wp.workersAmount) {
+			return false
+		}
+
+		if wp.workers.CompareAndSwap(current, current-1) {
+			return true
+		}
+	}
+}
+
+func (wp *WorkerPool[W]) startWorker() {
+	wp.workers.Add(1)
+	wp.wg.Add(1)
+
+	go wp.worker(wp.ctx)
 }
 
 func (wp *WorkerPool[W]) worker(ctx context.Context) {
 	defer wp.wg.Done()
 
+	var (
+		idleTimer *time.Timer
+		idleC     <-chan time.Time
+	)
+
+	if wp.maxWorkers > wp.workersAmount {
+		idleTimer = time.NewTimer(wp.idleTimeout)
+		defer idleTimer.Stop()
+
+		idleC = idleTimer.C
+	}
+
 	for {
 		select {
 		case <-ctx.Done():
+			wp.workers.Add(-1)
+
 			return
-		case work, ok := <-wp.worksChan:
-			if !ok {
+		case work := <-wp.worksChan:
+			wp.process(ctx, work)
+
+			if idleTimer != nil {
+				resetTimer(idleTimer, wp.idleTimeout)
+			}
+		case <-idleC:
+			if wp.retire() {
 				return
 			}
 
-			work.Do(ctx)
+			idleTimer.Reset(wp.idleTimeout)
+		}
+	}
+}
+
+func resetTimer(timer *time.Timer, d time.Duration) {
+	if !timer.Stop() {
+		select {
+		case <-timer.C:
+		default:
+		}
+	}
+
+	timer.Reset(d)
+}
+
+func (wp *WorkerPool[W]) process(ctx context.Context, work W) {
+	var err error
+
+	maxAttempts := wp.retryPolicy.maxAttempts()
+
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		if attempt > 1 {
+			if waitErr := wp.waitBackoff(ctx, attempt); waitErr != nil {
+				err = errors.Join(err, waitErr)
+
+				break
+			}
+		}
+
+		err = wp.do(ctx, work)
+		if err == nil {
+			return
+		}
+
+		var panicErr *PanicError
+		if errors.As(err, &panicErr) {
+			break
 		}
 	}
+
+	// пул закрыт посреди выполнения или ожидания повтора, задача остается незавершенной
+	if ctx.Err() != nil {
+		return
+	}
+
+	if wp.deadLetterSink != nil {
+		wp.deadLetterSink(work, err)
+	}
+}
+
+// do выполняет одну попытку задачи, ограничивая ее время и перехватывая панику.
+func (wp *WorkerPool[W]) do(ctx context.Context, work W) (err error) {
+	workCtx := ctx
+
+	if wp.workTimeout > 0 {
+		var cancel context.CancelFunc
+
+		workCtx, cancel = context.WithTimeout(ctx, wp.workTimeout)
+		defer cancel()
+	}
+
+	defer func() {
+		recovered := recover()
+		if recovered == nil {
+			return
+		}
+
+		stack := debug.Stack()
+
+		wp.panics.Add(1)
+
+		if wp.panicHandler != nil {
+			wp.panicHandler(work, recovered, stack)
+		}
+
+		err = &PanicError{Value: recovered, Stack: stack}
+	}()
+
+	err = work.Do(workCtx)
+
+	if err != nil && ctx.Err() == nil && errors.Is(workCtx.Err(), context.DeadlineExceeded) {
+		wp.timeouts.Add(1)
+	}
+
+	return err
+}
+
+func (wp *WorkerPool[W]) waitBackoff(ctx context.Context, attempt int) error {
+	delay := wp.retryPolicy.Backoff(attempt)
+	if delay <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
 }
 
 // ProcessQueue запускает цикл выполнения задач из очереди.
@@ -80,20 +400,27 @@ func (wp *WorkerPool[W]) ProcessQueue() {
 			cancel()
 		}()
 
+		wp.scaleMu.Lock()
+		defer wp.scaleMu.Unlock()
+
+		wp.ctx = ctx
+
 		for range wp.workersAmount {
-			wp.wg.Add(1)
-			go wp.worker(ctx)
+			wp.startWorker()
 		}
 	})
 }
 
-// Close оповещает воркеров об окончании работ, закрывает каналы.
+// Close оповещает воркеров об окончании работ.
+// Канал задач не закрывается, поэтому конкурентная постановка задач не приводит к панике.
 func (wp *WorkerPool[W]) Close() error {
+	wp.scaleMu.Lock()
+	defer wp.scaleMu.Unlock()
+
 	hasBeenCanceled := wp.closed.Swap(true)
 
 	if !hasBeenCanceled {
 		close(wp.doneChan)
-		close(wp.worksChan)
 	}
 
 	return nil
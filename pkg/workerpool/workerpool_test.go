@@ -0,0 +1,373 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errWorkFailed = errors.New("work failed")
+
+type flakyWork struct {
+	attempts atomic.Int32
+	failures int32
+	done     chan struct{}
+}
+
+func newFlakyWork(failures int32) *flakyWork {
+	return &flakyWork{
+		failures: failures,
+		done:     make(chan struct{}),
+	}
+}
+
+func (w *flakyWork) Do(_ context.Context) error {
+	if w.attempts.Add(1) <= w.failures {
+		return errWorkFailed
+	}
+
+	close(w.done)
+
+	return nil
+}
+
+type deadLetters struct {
+	works []*flakyWork
+	errs  []error
+	mu    sync.Mutex
+	wg    sync.WaitGroup
+}
+
+func (dl *deadLetters) sink(work *flakyWork, err error) {
+	dl.mu.Lock()
+	dl.works = append(dl.works, work)
+	dl.errs = append(dl.errs, err)
+	dl.mu.Unlock()
+
+	dl.wg.Done()
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{
+		MaxAttempts: 5,
+		BaseDelay:   10 * time.Millisecond,
+		MaxDelay:    30 * time.Millisecond,
+	}
+
+	assert.Equal(t, time.Duration(0), policy.Backoff(1))
+	assert.Equal(t, 10*time.Millisecond, policy.Backoff(2))
+	assert.Equal(t, 20*time.Millisecond, policy.Backoff(3))
+	assert.Equal(t, 30*time.Millisecond, policy.Backoff(4))
+	assert.Equal(t, 30*time.Millisecond, policy.Backoff(50))
+
+	policy.Jitter = 0.5
+
+	for range 100 {
+		delay := policy.Backoff(3)
+
+		assert.GreaterOrEqual(t, delay, 10*time.Millisecond)
+		assert.LessOrEqual(t, delay, 20*time.Millisecond)
+	}
+}
+
+func TestRetryPolicyBackoffWithoutMaxDelay(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: time.Second}
+
+	assert.Equal(t, 2*time.Second, policy.Backoff(3))
+	assert.Equal(t, DefaultMaxRetryDelay, policy.Backoff(100))
+	assert.Equal(t, DefaultMaxRetryDelay, policy.Backoff(math.MaxInt))
+}
+
+func TestWorkerPoolRetry(t *testing.T) {
+	const worksAmount = 50
+
+	policy := RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   time.Millisecond,
+		MaxDelay:    5 * time.Millisecond,
+		Jitter:      0.5,
+	}
+
+	t.Run("Retries failed works until success", func(t *testing.T) {
+		dl := &deadLetters{}
+		wp := New(4, Retry[*flakyWork](policy), DeadLetter(dl.sink))
+		wp.ProcessQueue()
+
+		works := make([]*flakyWork, 0, worksAmount)
+
+		for range worksAmount {
+			work := newFlakyWork(2)
+			works = append(works, work)
+
+			require.NoError(t, wp.QueueWork(work))
+		}
+
+		for _, work := range works {
+			<-work.done
+		}
+
+		wp.Close()
+		wp.Wait()
+
+		for _, work := range works {
+			assert.Equal(t, int32(3), work.attempts.Load())
+		}
+
+		assert.Empty(t, dl.works)
+	})
+
+	t.Run("Sends exhausted works to dead letter", func(t *testing.T) {
+		dl := &deadLetters{}
+		dl.wg.Add(worksAmount)
+
+		wp := New(4, Retry[*flakyWork](policy), DeadLetter(dl.sink))
+		wp.ProcessQueue()
+
+		for range worksAmount {
+			require.NoError(t, wp.QueueWork(newFlakyWork(int32(policy.MaxAttempts))))
+		}
+
+		dl.wg.Wait()
+		wp.Close()
+		wp.Wait()
+
+		require.Len(t, dl.works, worksAmount)
+
+		for i, work := range dl.works {
+			assert.Equal(t, int32(policy.MaxAttempts), work.attempts.Load())
+			assert.ErrorIs(t, dl.errs[i], errWorkFailed)
+		}
+	})
+
+	t.Run("Does not send work to dead letter when closed during backoff", func(t *testing.T) {
+		dl := &deadLetters{}
+
+		wp := New(1, Retry[*flakyWork](RetryPolicy{
+			MaxAttempts: 2,
+			BaseDelay:   time.Hour,
+		}), DeadLetter(dl.sink))
+		wp.ProcessQueue()
+
+		work := newFlakyWork(1)
+		require.NoError(t, wp.QueueWork(work))
+
+		require.Eventually(t, func() bool {
+			return work.attempts.Load() == 1
+		}, time.Second, time.Millisecond)
+
+		wp.Close()
+		wp.Wait()
+
+		assert.Empty(t, dl.errs)
+		assert.Equal(t, int32(1), work.attempts.Load())
+	})
+}
+
+type blockingWork struct {
+	release chan struct{}
+}
+
+func (w *blockingWork) Do(ctx context.Context) error {
+	select {
+	case <-w.release:
+	case <-ctx.Done():
+	}
+
+	return nil
+}
+
+func TestWorkerPoolQueue(t *testing.T) {
+	t.Run("TryQueueWork returns error for full queue", func(t *testing.T) {
+		wp := New(1, QueueSize[*blockingWork](1))
+		defer wp.Close()
+
+		require.NoError(t, wp.TryQueueWork(&blockingWork{}))
+		assert.ErrorIs(t, wp.TryQueueWork(&blockingWork{}), ErrQueueFull)
+	})
+
+	t.Run("QueueWorkContext waits for free slot until timeout", func(t *testing.T) {
+		wp := New(1, QueueSize[*blockingWork](1))
+		defer wp.Close()
+
+		require.NoError(t, wp.TryQueueWork(&blockingWork{}))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		err := wp.QueueWorkContext(ctx, &blockingWork{})
+		assert.ErrorIs(t, err, ErrQueueFull)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("QueueWorkContext enqueues when worker frees slot", func(t *testing.T) {
+		wp := New(1, QueueSize[*blockingWork](1))
+		wp.ProcessQueue()
+
+		first := &blockingWork{release: make(chan struct{})}
+		require.NoError(t, wp.QueueWork(first))
+		require.NoError(t, wp.QueueWork(&blockingWork{release: make(chan struct{})}))
+
+		go close(first.release)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		require.NoError(t, wp.QueueWorkContext(ctx, &blockingWork{}))
+
+		wp.Close()
+		wp.Wait()
+	})
+
+	t.Run("Queueing concurrently with close is safe", func(t *testing.T) {
+		wp := New(2, QueueSize[*blockingWork](1))
+		wp.ProcessQueue()
+
+		var wg sync.WaitGroup
+
+		for range 16 {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				for range 100 {
+					err := wp.QueueWork(&blockingWork{})
+					if errors.Is(err, ErrHasBeenAlreadyClosed) {
+						return
+					}
+				}
+			}()
+		}
+
+		wp.Close()
+		wg.Wait()
+		wp.Wait()
+
+		assert.ErrorIs(t, wp.TryQueueWork(&blockingWork{}), ErrHasBeenAlreadyClosed)
+	})
+}
+
+func TestWorkerPoolAutoscaling(t *testing.T) {
+	wp := New(
+		1,
+		MaxWorkers[*blockingWork](3),
+		IdleTimeout[*blockingWork](10*time.Millisecond),
+		QueueSize[*blockingWork](8),
+	)
+	wp.ProcessQueue()
+
+	assert.Equal(t, 1, wp.Workers())
+
+	release := make(chan struct{})
+
+	for range 8 {
+		require.NoError(t, wp.QueueWork(&blockingWork{release: release}))
+	}
+
+	require.Eventually(t, func() bool {
+		return wp.Workers() == 3
+	}, time.Second, time.Millisecond)
+
+	assert.Positive(t, wp.QueueLen())
+
+	close(release)
+
+	require.Eventually(t, func() bool {
+		return wp.QueueLen() == 0 && wp.Workers() == 1
+	}, time.Second, time.Millisecond)
+
+	wp.Close()
+	wp.Wait()
+
+	assert.Equal(t, 0, wp.Workers())
+}
+
+type funcWork func(ctx context.Context) error
+
+func (w funcWork) Do(ctx context.Context) error {
+	return w(ctx)
+}
+
+func TestWorkerPoolIsolation(t *testing.T) {
+	t.Run("Recovers panics and sends work to dead letter without retries", func(t *testing.T) {
+		var (
+			attempts  atomic.Int32
+			recovered atomic.Value
+			deadErr   = make(chan error, 1)
+		)
+
+		wp := New(
+			1,
+			Retry[funcWork](RetryPolicy{MaxAttempts: 3}),
+			OnPanic(func(_ funcWork, r any, stack []byte) {
+				recovered.Store(r)
+				assert.NotEmpty(t, stack)
+			}),
+			DeadLetter(func(_ funcWork, err error) {
+				deadErr <- err
+			}),
+		)
+		wp.ProcessQueue()
+
+		require.NoError(t, wp.QueueWork(func(_ context.Context) error {
+			attempts.Add(1)
+			panic("boom")
+		}))
+
+		err := <-deadErr
+
+		var panicErr *PanicError
+
+		require.ErrorAs(t, err, &panicErr)
+		assert.Equal(t, "boom", panicErr.Value)
+		assert.Equal(t, "boom", recovered.Load())
+		assert.Equal(t, int32(1), attempts.Load())
+
+		wp.Close()
+		wp.Wait()
+
+		assert.Equal(t, int64(1), wp.Stats().Panics)
+	})
+
+	t.Run("Applies deadline to every attempt", func(t *testing.T) {
+		deadErr := make(chan error, 1)
+
+		wp := New(
+			2,
+			Retry[funcWork](RetryPolicy{MaxAttempts: 2}),
+			WorkTimeout[funcWork](10*time.Millisecond),
+			DeadLetter(func(_ funcWork, err error) {
+				deadErr <- err
+			}),
+		)
+		wp.ProcessQueue()
+
+		require.NoError(t, wp.QueueWork(func(ctx context.Context) error {
+			<-ctx.Done()
+
+			return ctx.Err()
+		}))
+
+		done := make(chan struct{})
+		require.NoError(t, wp.QueueWork(func(_ context.Context) error {
+			close(done)
+
+			return nil
+		}))
+
+		<-done
+		assert.ErrorIs(t, <-deadErr, context.DeadlineExceeded)
+
+		wp.Close()
+		wp.Wait()
+
+		assert.Equal(t, int64(2), wp.Stats().Timeouts)
+	})
+}